@@ -204,3 +204,51 @@ func ToDuration(v any) (time.Duration, bool) {
 	}
 	return time.Duration(0), false
 }
+
+// ToByteSize convert size representation to number of bytes.
+// Accepted string format is number with optional unit, e.g. "512", "64KB", "1.5 GiB".
+// Units K, KB and KiB (also M, G, T) are treated as power of 1024.
+func ToByteSize(v any) (int64, bool) {
+	var s string
+	switch sv := v.(type) {
+	case string:
+		s = sv
+	case []byte:
+		s = string(sv)
+	case float32, float64:
+		f, _ := ToFloat(v)
+		return int64(f), true
+	default:
+		return ToInt(v)
+	}
+
+	s = strings.ToUpper(strings.TrimSpace(s))
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	num, unit := s, ""
+	if idx >= 0 {
+		num, unit = s[:idx], strings.TrimSpace(s[idx:])
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "B"), "I")
+	mul := int64(1)
+	switch unit {
+	case "":
+	case "K":
+		mul = 1 << 10
+	case "M":
+		mul = 1 << 20
+	case "G":
+		mul = 1 << 30
+	case "T":
+		mul = 1 << 40
+	default:
+		return 0, false
+	}
+	return int64(f * float64(mul)), true
+}
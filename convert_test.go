@@ -47,3 +47,26 @@ func TestConvertion(t *testing.T) {
 		assert.Equal(t, iv.Time, ok, "ToTime>%d: %#v", i, iv.val)
 	}
 }
+
+func TestToByteSize(t *testing.T) {
+	sizes := map[any]int64{
+		"512":     512,
+		"10B":     10,
+		"64KB":    64 << 10,
+		"64k":     64 << 10,
+		"100MB":   100 << 20,
+		"1.5 GiB": 3 << 29,
+		"2T":      2 << 40,
+		4096:      4096,
+		8.0:       8,
+	}
+	for v, exp := range sizes {
+		n, ok := pola.ToByteSize(v)
+		assert.True(t, ok, "%v", v)
+		assert.Equal(t, exp, n, "%v", v)
+	}
+	for _, v := range []any{"", "MB", "1PB", "-1KB", nil} {
+		_, ok := pola.ToByteSize(v)
+		assert.False(t, ok, "%v", v)
+	}
+}
@@ -0,0 +1,179 @@
+package pola
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCronSpec = errors.New("invalid cron spec")
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+	cronMonths = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDays   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// CronSchedule is parsed standard 5-field cron expression:
+// minute, hour, day of month, month and day of week.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+type cronField struct {
+	min, max int
+	names    []string
+}
+
+var (
+	cronMinute = cronField{0, 59, nil}
+	cronHour   = cronField{0, 23, nil}
+	cronDom    = cronField{1, 31, nil}
+	cronMonth  = cronField{1, 12, cronMonths}
+	cronDow    = cronField{0, 7, cronDays}
+)
+
+// ParseCron parse standard 5-field cron expression.
+// Each field accepts `*`, single value, range `a-b`, step `*/n` or `a-b/n`
+// and comma separated list of them. Month and day of week also accept
+// three letters names (JAN-DEC, SUN-SAT), and day of week 7 is Sunday.
+// Descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight
+// and @hourly are supported as well.
+func ParseCron(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: `%s`, expecting 5 fields", ErrInvalidCronSpec, spec)
+	}
+
+	var err error
+	cs := CronSchedule{
+		domAny: fields[2] == "*" || fields[2] == "?",
+		dowAny: fields[4] == "*" || fields[4] == "?",
+	}
+	if cs.minute, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if cs.hour, err = cronHour.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if cs.dom, err = cronDom.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if cs.month, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if cs.dow, err = cronDow.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// 7 is Sunday
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+
+	return &cs, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: value `%s` out of range [%d-%d]", ErrInvalidCronSpec, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("%w: invalid step `%s`", ErrInvalidCronSpec, part)
+			}
+			step = s
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" && rng != "?" {
+			los, his, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(los); err != nil {
+				return 0, err
+			}
+			if isRange {
+				if hi, err = f.value(his); err != nil {
+					return 0, err
+				}
+			} else if !hasStep {
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%w: invalid range `%s`", ErrInvalidCronSpec, part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *CronSchedule) dayMatch(t time.Time) bool {
+	domOk := c.dom&(1<<t.Day()) != 0
+	dowOk := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domOk && dowOk
+	}
+	// both restricted, either one matches
+	return domOk || dowOk
+}
+
+// Next return the first activation time after t,
+// or zero time if no activation is found within five years.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -46,6 +46,7 @@ type faDecoder struct {
 }
 
 // NewFsDecoder decode given file into object.
+// Compressed file (e.g. .yaml.gz, .json.zst) is decompressed before decoding.
 // Supported format and corresponding decoders are:
 // - json: encoding/json
 // - hjson: github.com/hjson/hjson-go/v4
@@ -69,14 +70,15 @@ func (d *faDecoder) Decode(dest any) error {
 	}
 
 	var errs error
-	ext := strings.ToLower(filepath.Ext(d.name))
+	base, comp := SplitCompressExt(d.name)
+	ext := strings.ToLower(filepath.Ext(base))
 	for _, f := range fa {
 		rdr, err := f.Open(d.name)
 		if err != nil {
 			errs = errors.Join(errs, err)
 			continue
 		}
-		err = NewDecoder(rdr, ext).Decode(dest)
+		err = decodeCompressed(rdr, ext, comp, dest)
 		rdr.Close()
 
 		if err == nil {
@@ -89,6 +91,19 @@ func (d *faDecoder) Decode(dest any) error {
 	return errs
 }
 
+func decodeCompressed(r io.Reader, ext, compression string, dest any) error {
+	if compression == "" {
+		return NewDecoder(r, ext).Decode(dest)
+	}
+	dr, err := NewDecompressReader(r, compression)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	return NewDecoder(dr, ext).Decode(dest)
+}
+
 type rdDecoder struct {
 	rdr io.Reader
 	ext string
@@ -141,6 +156,10 @@ func (r *rdDecoder) decodeHjson(dest any) error {
 }
 
 func (r *rdDecoder) Decode(dest any) error {
+	// e.g. .yaml.gz
+	if ext, comp := SplitCompressExt(r.ext); comp != "" {
+		return decodeCompressed(r.rdr, strings.ToLower(ext), comp, dest)
+	}
 	switch r.ext {
 	case ExtJson:
 		return json.NewDecoder(r.rdr).Decode(dest)
@@ -7,12 +7,15 @@ toolchain go1.24.1
 require (
 	github.com/BurntSushi/toml v1.6.0
 	github.com/TylerBrock/colorjson v0.0.0-20200706003622-8a50f05110d2
+	github.com/dsnet/compress v0.0.1
 	github.com/goccy/go-yaml v1.19.2
 	github.com/google/go-jsonnet v0.21.0
 	github.com/hjson/hjson-go/v4 v4.5.0
 	github.com/k0kubun/pp/v3 v3.5.0
+	github.com/klauspost/compress v1.18.0
 	github.com/stretchr/testify v1.10.0
 	github.com/tailscale/hujson v0.0.0-20250605163823-992244df8c5a
+	github.com/ulikunitz/xz v0.5.12
 )
 
 require (
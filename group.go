@@ -0,0 +1,69 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Group is a Runner which executes several Runners concurrently
+// sharing the same lifecycle. When one of the Runners fails,
+// the rest are canceled. Group can be passed directly to Interruptible.
+type Group []Runner
+
+// NewGroup create Group from given runners.
+func NewGroup(rs ...Runner) Group {
+	return Group(rs)
+}
+
+// Add append runner into the group.
+func (g *Group) Add(r Runner) {
+	*g = append(*g, r)
+}
+
+// AddFunc append function as runner into the group.
+func (g *Group) AddFunc(fn func(ctx context.Context) error) {
+	g.Add(RunnerFunc(fn))
+}
+
+// RunContext starts all runners and waits until all of them return.
+// The first failing runner cancels the others. Errors from all runners
+// are joined, except context.Canceled returned by runners which were
+// canceled because of another runner's failure.
+func (g Group) RunContext(ctx context.Context) error {
+	if len(g) == 0 {
+		return nil
+	}
+
+	cctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	errs := make([]error, len(g))
+	wg := sync.WaitGroup{}
+	for i, r := range g {
+		if r == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.RunContext(cctx); err != nil {
+				errs[i] = err
+				cancel(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// drop cancellation errors caused by a failing sibling
+	cause := context.Cause(cctx)
+	if ctx.Err() == nil && cause != nil {
+		for i, err := range errs {
+			if err != cause && errors.Is(err, context.Canceled) {
+				errs[i] = nil
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
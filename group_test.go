@@ -0,0 +1,49 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	g := pola.NewGroup()
+	g.AddFunc(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return errA
+	})
+	g.AddFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.AddFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return errB
+	})
+	err := g.RunContext(context.Background())
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+	assert.NotErrorIs(t, err, context.Canceled)
+
+	// all runners succeeded
+	g = pola.NewGroup(pola.RunnerFunc(func(ctx context.Context) error {
+		return nil
+	}), nil)
+	assert.NoError(t, g.RunContext(context.Background()))
+
+	// canceled by parent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g = pola.NewGroup(pola.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	assert.ErrorIs(t, pola.InterruptibleContext(ctx, g), context.Canceled)
+}
@@ -2,9 +2,20 @@ package pola
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"os/signal"
+	"runtime/pprof"
+	"slices"
+	"sync"
 	"syscall"
+	"time"
+)
+
+var (
+	ErrShutdownTimeout = errors.New("shutdown timeout")
+	ErrForcedShutdown  = errors.New("forced shutdown")
 )
 
 // RunnerFunc is adapter to allow function to be used as Runner
@@ -19,6 +30,140 @@ func (f RunnerFunc) RunContext(ctx context.Context) error {
 	return f(ctx)
 }
 
+// InterruptOptions configure how InterruptibleOptions handle signals
+// and shutdown of the Runner.
+type InterruptOptions struct {
+	// Signals is list of additional signals (beside os.Interrupt and syscall.SIGINT)
+	// which cancel the Runner.
+	Signals []os.Signal
+
+	// GracePeriod is the maximum time to wait for the Runner (and pending actions)
+	// to return once its context is canceled. Zero means wait forever.
+	GracePeriod time.Duration
+
+	// OnSignal, if not nil, is called for every captured signal.
+	OnSignal func(os.Signal)
+
+	// Actions maps non-terminal signals (e.g. SIGHUP, SIGUSR1) to the action
+	// which should be executed when the signal is captured. The Runner keeps
+	// running, and the action is executed in a separate goroutine with the Runner
+	// context. Actions are executed one at a time, so slow action delays
+	// the next action but not signal handling. Signals received while
+	// the action is pending are coalesced into single execution.
+	Actions map[os.Signal]SignalAction
+
+	// OnActionError, if not nil, is called (from action goroutine)
+	// when an action returns error.
+	OnActionError func(os.Signal, error)
+
+	// Source deliver signals, default to OsSignals.
+	Source SignalSource
+}
+
+// SignalSource deliver incoming signals to a channel, see os/signal.
+type SignalSource interface {
+	Notify(c chan<- os.Signal, sig ...os.Signal)
+	Stop(c chan<- os.Signal)
+}
+
+type osSignalSource struct{}
+
+// OsSignals is SignalSource backed by os/signal package.
+var OsSignals SignalSource = osSignalSource{}
+
+func (osSignalSource) Notify(c chan<- os.Signal, sig ...os.Signal) {
+	signal.Notify(c, sig...)
+}
+func (osSignalSource) Stop(c chan<- os.Signal) {
+	signal.Stop(c)
+}
+
+// FakeSignalSource is SignalSource which deliver signals sent through Send.
+// It is intended for testing code driven by Interruptible.
+type FakeSignalSource struct {
+	mu   sync.Mutex
+	subs map[chan<- os.Signal][]os.Signal
+}
+
+// NewFakeSignalSource create fake signal source.
+func NewFakeSignalSource() *FakeSignalSource {
+	return &FakeSignalSource{subs: make(map[chan<- os.Signal][]os.Signal)}
+}
+
+func (f *FakeSignalSource) Notify(c chan<- os.Signal, sig ...os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subs[c] = append(f.subs[c], sig...)
+}
+func (f *FakeSignalSource) Stop(c chan<- os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.subs, c)
+}
+
+// Send deliver signal to channels registered for it.
+// As with os/signal, delivery does not block, and it returns
+// the number of channels which received the signal.
+func (f *FakeSignalSource) Send(sig os.Signal) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for c, sigs := range f.subs {
+		if slices.Contains(sigs, sig) {
+			select {
+			case c <- sig:
+				n++
+			default:
+			}
+		}
+	}
+	return n
+}
+
+// Len return number of registered channels.
+func (f *FakeSignalSource) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.subs)
+}
+
+// SignalAction is executed when the associated signal is captured.
+type SignalAction func(ctx context.Context, sig os.Signal) error
+
+// ReloadAction create SignalAction which call fn, e.g. to reload configuration.
+func ReloadAction(fn func(ctx context.Context) error) SignalAction {
+	return func(ctx context.Context, _ os.Signal) error {
+		return fn(ctx)
+	}
+}
+
+// DecodeAction create SignalAction which decode content using new Decoder
+// returned by `newDec` into a new value of T, and pass it to `apply` when succeeded.
+// A fresh Decoder is needed for every signal, since decoder may be consumed
+// by a decode, e.g. NewDecoder(r, ext).
+func DecodeAction[T any](newDec func() Decoder, apply func(*T)) SignalAction {
+	return func(ctx context.Context, _ os.Signal) error {
+		v := new(T)
+		if err := newDec().Decode(v); err != nil {
+			return err
+		}
+		apply(v)
+		return nil
+	}
+}
+
+// DumpGoroutinesAction create SignalAction which write stack traces
+// of all goroutines into w.
+func DumpGoroutinesAction(w io.Writer) SignalAction {
+	return func(ctx context.Context, _ os.Signal) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 2)
+	}
+}
+
 // InterruptibleFunc execute runner with given function
 func InterruptibleFunc(fn func(ctx context.Context) error, sigs ...os.Signal) error {
 	var rf RunnerFunc = fn
@@ -38,38 +183,128 @@ func Interruptible(r Runner, sigs ...os.Signal) error {
 // By default, the function listen to os.Interrupt and syscall.SIGINT,
 // if additional signals are needed, pass them to optional `sigs` argument.
 func InterruptibleContext(ctx context.Context, r Runner, sigs ...os.Signal) error {
+	return InterruptibleOptions(ctx, r, InterruptOptions{Signals: sigs})
+}
+
+// InterruptibleOptions execute Runner and cancel it when one of the signals is captured.
+// Signals listed in `opts.Actions` do not cancel the Runner, instead the action is executed.
+// Once canceled (by signal or by parent context), the Runner and pending actions
+// are given `opts.GracePeriod` to return, otherwise ErrShutdownTimeout is returned.
+// A second signal received during shutdown force immediate return with ErrForcedShutdown.
+// In both cases the Runner (and pending actions) is left running in the background.
+func InterruptibleOptions(ctx context.Context, r Runner, opts InterruptOptions) error {
 	cctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// actions run outside the signal loop, one at a time.
+	// Pending actions are coalesced per signal.
+	var actMu sync.Mutex
+	var pending []os.Signal
+	wake := make(chan struct{}, 1)
+	actDone := make(chan struct{})
+	queueAction := func(sig os.Signal) {
+		actMu.Lock()
+		if !slices.Contains(pending, sig) {
+			pending = append(pending, sig)
+		}
+		actMu.Unlock()
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+	nextAction := func() (os.Signal, bool) {
+		actMu.Lock()
+		defer actMu.Unlock()
+		if len(pending) == 0 {
+			return nil, false
+		}
+		sig := pending[0]
+		pending = pending[1:]
+		return sig, true
+	}
+	go func() {
+		defer close(actDone)
+		for range wake {
+			for sig, ok := nextAction(); ok; sig, ok = nextAction() {
+				if err := opts.Actions[sig](cctx, sig); err != nil && opts.OnActionError != nil {
+					opts.OnActionError(sig, err)
+				}
+			}
+		}
+	}()
+	stopActions := sync.OnceFunc(func() { close(wake) })
+	defer stopActions()
+
 	chSigs := make(chan os.Signal, 1)
-	chDone := make(chan bool, 1)
-	chRunDone := make(chan bool, 1)
+	chErr := make(chan error, 1)
+
+	notif := []os.Signal{os.Interrupt, syscall.SIGINT}
+	notif = append(notif, opts.Signals...)
+	for sig := range opts.Actions {
+		notif = append(notif, sig)
+	}
+	src := opts.Source
+	if src == nil {
+		src = OsSignals
+	}
+	src.Notify(chSigs, notif...)
+	defer src.Stop(chSigs)
+
+	go func() {
+		chErr <- r.RunContext(cctx)
+	}()
 
 	// Handle several cases:
 	// 1. Signal retrieved
 	// 2. App/task done
 	// 3. Canceled by other through parent context
-	notif := []os.Signal{os.Interrupt, syscall.SIGINT}
-	notif = append(notif, sigs...)
-	signal.Notify(chSigs, notif...)
-	go func() {
-		defer close(chDone)
+	// 4. Pending actions done, after the Runner returned
+	var chTimeout <-chan time.Time
+	var chActDone <-chan struct{}
+	var runErr error
+	shutdown := false
+	startShutdown := func() {
+		shutdown = true
+		cancel()
+		if opts.GracePeriod > 0 {
+			chTimeout = time.After(opts.GracePeriod)
+		}
+	}
+	done := cctx.Done()
+	for {
 		select {
-		case <-chSigs:
-			cancel()
-			return
-		case <-chRunDone:
-			return
-		case <-cctx.Done():
-			return
+		case sig := <-chSigs:
+			if opts.OnSignal != nil {
+				opts.OnSignal(sig)
+			}
+			if _, ok := opts.Actions[sig]; ok {
+				if chActDone == nil {
+					queueAction(sig)
+				}
+				continue
+			}
+			if shutdown {
+				return ErrForcedShutdown
+			}
+			startShutdown()
+		case runErr = <-chErr:
+			// wait pending actions (which see canceled context) within grace period
+			chErr = nil
+			stopActions()
+			chActDone = actDone
+			if !shutdown {
+				startShutdown()
+			}
+		case <-chActDone:
+			return runErr
+		case <-done:
+			done = nil
+			if !shutdown {
+				startShutdown()
+			}
+		case <-chTimeout:
+			return errors.Join(runErr, ErrShutdownTimeout)
 		}
-	}()
-
-	err := r.RunContext(cctx)
-	close(chRunDone)
-
-	// wait until go routine done
-	<-chDone
-
-	return err
+	}
 }
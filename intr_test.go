@@ -0,0 +1,239 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptibleDone(t *testing.T) {
+	errRun := errors.New("run failed")
+	err := pola.InterruptibleFunc(func(ctx context.Context) error {
+		return errRun
+	})
+	assert.ErrorIs(t, err, errRun)
+}
+
+func TestInterruptibleGracePeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+	opts := pola.InterruptOptions{GracePeriod: 50 * time.Millisecond}
+	err := pola.InterruptibleOptions(ctx, pola.RunnerFunc(func(ctx context.Context) error {
+		<-block
+		return nil
+	}), opts)
+	assert.ErrorIs(t, err, pola.ErrShutdownTimeout)
+}
+
+func TestInterruptibleSignalSource(t *testing.T) {
+	src := pola.NewFakeSignalSource()
+	started := make(chan struct{})
+	sigs := make(chan os.Signal, 2)
+	opts := pola.InterruptOptions{
+		Source:   src,
+		OnSignal: func(s os.Signal) { sigs <- s },
+	}
+
+	// signal cancel the runner
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}), opts)
+	}()
+	<-started
+	assert.Equal(t, 1, src.Len())
+	assert.Equal(t, 0, src.Send(syscall.SIGTERM))
+	assert.Equal(t, 1, src.Send(os.Interrupt))
+	assert.ErrorIs(t, <-chErr, context.Canceled)
+	assert.Equal(t, os.Interrupt, <-sigs)
+	assert.Equal(t, 0, src.Len())
+
+	// second signal force exit
+	block := make(chan struct{})
+	defer close(block)
+	started = make(chan struct{})
+	go func() {
+		chErr <- pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+			close(started)
+			<-block
+			return nil
+		}), opts)
+	}()
+	<-started
+	src.Send(os.Interrupt)
+	<-sigs
+	src.Send(os.Interrupt)
+	assert.ErrorIs(t, <-chErr, pola.ErrForcedShutdown)
+	assert.Equal(t, 0, src.Len())
+
+	// runner done
+	err := pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+		return nil
+	}), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, src.Len())
+
+	// parent canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = pola.InterruptibleOptions(ctx, pola.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}), opts)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, src.Len())
+}
+
+func TestInterruptibleSlowAction(t *testing.T) {
+	src := pola.NewFakeSignalSource()
+	started := make(chan struct{})
+	sigs := make(chan os.Signal, 2)
+	actDone := false
+	opts := pola.InterruptOptions{
+		Source:   src,
+		OnSignal: func(s os.Signal) { sigs <- s },
+		Actions: map[os.Signal]pola.SignalAction{
+			// block until runner is canceled
+			syscall.SIGHUP: func(ctx context.Context, _ os.Signal) error {
+				<-ctx.Done()
+				actDone = true
+				return nil
+			},
+		},
+	}
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}), opts)
+	}()
+	<-started
+	src.Send(syscall.SIGHUP)
+	<-sigs
+	src.Send(os.Interrupt)
+	assert.ErrorIs(t, <-chErr, context.Canceled)
+	assert.True(t, actDone)
+}
+
+func TestInterruptibleHungAction(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	hung := func(ctx context.Context, _ os.Signal) error {
+		<-block
+		return nil
+	}
+
+	for _, force := range []bool{false, true} {
+		src := pola.NewFakeSignalSource()
+		sigs := make(chan os.Signal, 2)
+		opts := pola.InterruptOptions{
+			Source:   src,
+			OnSignal: func(s os.Signal) { sigs <- s },
+			Actions:  map[os.Signal]pola.SignalAction{syscall.SIGHUP: hung},
+		}
+		if !force {
+			opts.GracePeriod = 50 * time.Millisecond
+		}
+		chErr := make(chan error, 1)
+		go func() {
+			chErr <- pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}), opts)
+		}()
+		for src.Len() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		src.Send(syscall.SIGHUP)
+		<-sigs
+		src.Send(os.Interrupt)
+		<-sigs
+		if force {
+			src.Send(os.Interrupt)
+			assert.ErrorIs(t, <-chErr, pola.ErrForcedShutdown)
+		} else {
+			err := <-chErr
+			assert.ErrorIs(t, err, pola.ErrShutdownTimeout)
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	}
+}
+
+func TestInterruptibleCoalesceActions(t *testing.T) {
+	src := pola.NewFakeSignalSource()
+	sigs := make(chan os.Signal, 1)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var count atomic.Int32
+	opts := pola.InterruptOptions{
+		Source:   src,
+		OnSignal: func(s os.Signal) { sigs <- s },
+		Actions: map[os.Signal]pola.SignalAction{
+			syscall.SIGHUP: func(ctx context.Context, _ os.Signal) error {
+				count.Add(1)
+				started <- struct{}{}
+				<-release
+				return nil
+			},
+		},
+	}
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}), opts)
+	}()
+	for src.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	src.Send(syscall.SIGHUP)
+	<-sigs
+	<-started
+	for range 5 {
+		src.Send(syscall.SIGHUP)
+		<-sigs
+	}
+	close(release)
+	<-started
+	src.Send(os.Interrupt)
+	<-sigs
+	assert.NoError(t, <-chErr)
+	assert.Equal(t, int32(2), count.Load())
+}
+
+func TestDecodeAction(t *testing.T) {
+	type config struct {
+		Name string `json:"name"`
+	}
+	pth := filepath.Join(t.TempDir(), "cfg.json")
+	assert.NoError(t, os.WriteFile(pth, []byte(`{"name": "a"}`), 0o644))
+
+	var cfg *config
+	act := pola.DecodeAction(func() pola.Decoder { return pola.NewFsDecoder(pth) }, func(c *config) {
+		cfg = c
+	})
+	assert.NoError(t, act(context.Background(), syscall.SIGHUP))
+	assert.Equal(t, "a", cfg.Name)
+
+	// decode again on next signal
+	assert.NoError(t, os.WriteFile(pth, []byte(`{"name": "b"}`), 0o644))
+	assert.NoError(t, act(context.Background(), syscall.SIGHUP))
+	assert.Equal(t, "b", cfg.Name)
+}
@@ -0,0 +1,84 @@
+//go:build unix
+
+package pola_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptibleSignal(t *testing.T) {
+	var sigs []os.Signal
+	block := make(chan struct{})
+	defer close(block)
+
+	opts := pola.InterruptOptions{
+		Signals: []os.Signal{syscall.SIGUSR2},
+		OnSignal: func(s os.Signal) {
+			sigs = append(sigs, s)
+		},
+	}
+	started := make(chan struct{})
+	go func() {
+		<-started
+		syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+		time.Sleep(20 * time.Millisecond)
+		syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	}()
+	err := pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		<-block
+		return ctx.Err()
+	}), opts)
+	assert.ErrorIs(t, err, pola.ErrForcedShutdown)
+	assert.Equal(t, []os.Signal{syscall.SIGUSR2, syscall.SIGUSR2}, sigs)
+}
+
+func TestInterruptibleActions(t *testing.T) {
+	type config struct {
+		Debug bool `json:"debug"`
+	}
+	var cfg *config
+	dump := bytes.Buffer{}
+	errAct := errors.New("action failed")
+	var actErr error
+
+	opts := pola.InterruptOptions{
+		Signals: []os.Signal{syscall.SIGTERM},
+		Actions: map[os.Signal]pola.SignalAction{
+			syscall.SIGHUP: pola.DecodeAction(func() pola.Decoder { return pola.JsonText(`{"debug": true}`) }, func(c *config) {
+				cfg = c
+			}),
+			syscall.SIGUSR1: pola.DumpGoroutinesAction(&dump),
+			syscall.SIGUSR2: pola.ReloadAction(func(ctx context.Context) error {
+				return errAct
+			}),
+		},
+		OnActionError: func(s os.Signal, err error) {
+			actErr = err
+		},
+	}
+	go func() {
+		for _, sig := range []syscall.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGTERM} {
+			time.Sleep(20 * time.Millisecond)
+			syscall.Kill(os.Getpid(), sig)
+		}
+	}()
+	err := pola.InterruptibleOptions(context.Background(), pola.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, &config{Debug: true}, cfg)
+	assert.Contains(t, dump.String(), "goroutine")
+	assert.ErrorIs(t, actErr, errAct)
+}
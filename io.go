@@ -1,14 +1,18 @@
 package pola
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
-	"net"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -20,6 +24,11 @@ const (
 	IoEmpty   = ""
 )
 
+var (
+	ErrNotReadable = errors.New("descriptor is not readable")
+	ErrNotWritable = errors.New("descriptor is not writable")
+)
+
 // CurrentDirFS return fs.FS for current working directory
 func CurrentDirFS() (fs.FS, error) {
 	pwd, err := os.Getwd()
@@ -117,12 +126,276 @@ func (n nopRWCloserReadFromWriteTo) ReadFrom(r io.Reader) (int64, error) {
 	return n.ReadWriter.(io.ReaderFrom).ReadFrom(r)
 }
 
+// readCloser combine reader with closer of the underlying stream
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// writeCloser combine writer with closer of the underlying stream
+type writeCloser struct {
+	io.Writer
+	io.Closer
+}
+
+// ReadCloser with Write method which always fail
+type readOnlyRWCloser struct {
+	io.ReadCloser
+}
+
+func (readOnlyRWCloser) Write(p []byte) (int, error) {
+	return 0, ErrNotWritable
+}
+
+// WriteCloser with Read method which always fail
+type writeOnlyRWCloser struct {
+	io.WriteCloser
+}
+
+func (writeOnlyRWCloser) Read(p []byte) (int, error) {
+	return 0, ErrNotReadable
+}
+
+// CountingReader count number of bytes read.
+// It is safe to call Count while reading from other goroutine.
+type CountingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+// NewCountingReader wrap reader with byte counter.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+// Count return number of bytes read so far.
+func (c *CountingReader) Count() int64 {
+	return c.n.Load()
+}
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+func (c *CountingReader) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := c.r.(io.WriterTo); ok {
+		n, err := wt.WriteTo(w)
+		c.n.Add(n)
+		return n, err
+	}
+	return copyBuffer(w, onlyReader{c})
+}
+
+// CountingWriter count number of bytes written.
+// It is safe to call Count while writing from other goroutine.
+type CountingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+// NewCountingWriter wrap writer with byte counter.
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{w: w}
+}
+
+// Count return number of bytes written so far.
+func (c *CountingWriter) Count() int64 {
+	return c.n.Load()
+}
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+func (c *CountingWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := c.w.(io.ReaderFrom); ok {
+		n, err := rf.ReadFrom(r)
+		c.n.Add(n)
+		return n, err
+	}
+	return copyBuffer(onlyWriter{c}, r)
+}
+
+// ProgressReader call the callback with total bytes read after every read.
+type ProgressReader struct {
+	r        io.Reader
+	total    int64
+	read     int64
+	progress func(read, total int64)
+}
+
+// NewProgressReader wrap reader with progress callback.
+// Argument `total` is the expected size (or -1 if unknown),
+// which is passed as is to the callback.
+func NewProgressReader(r io.Reader, total int64, progress func(read, total int64)) *ProgressReader {
+	return &ProgressReader{r: r, total: total, progress: progress}
+}
+
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.progress(p.read, p.total)
+	}
+	return n, err
+}
+
+// tokenBucket limit throughput to `rate` bytes per second with burst of `rate` bytes.
+// Nil bucket (rate <= 0) does not limit.
+type tokenBucket struct {
+	sync.Mutex
+	ctx    context.Context
+	clock  Clock
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(ctx context.Context, rate int64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{ctx: ctx, clock: SystemClock, rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// setClock replace the clock, must be called before transfer.
+func (b *tokenBucket) setClock(c Clock) {
+	if b == nil {
+		return
+	}
+	b.clock = c
+	b.last = c.Now()
+}
+
+// chunk return maximum size of single read/write
+func (b *tokenBucket) chunk(n int) int {
+	if b == nil {
+		return n
+	}
+	return max(min(n, int(b.rate)), 1)
+}
+
+// wait until n bytes can be transferred or the context is canceled
+func (b *tokenBucket) wait(n int) error {
+	if b == nil {
+		return nil
+	}
+	b.Lock()
+	now := b.clock.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	debt := b.tokens
+	b.Unlock()
+
+	if debt < 0 {
+		select {
+		case <-b.clock.After(time.Duration(-debt / b.rate * float64(time.Second))):
+		case <-b.ctx.Done():
+			return b.ctx.Err()
+		}
+	}
+	return nil
+}
+
+// RateLimitedReader limit reading throughput to given bytes per second.
+type RateLimitedReader struct {
+	r  io.Reader
+	tb *tokenBucket
+}
+
+// NewRateLimitedReader wrap reader with token bucket limiter (bytes/second).
+// If rate <= 0, reading is not limited.
+func NewRateLimitedReader(r io.Reader, rate int64) *RateLimitedReader {
+	return &RateLimitedReader{r: r, tb: newTokenBucket(context.Background(), rate)}
+}
+
+// SetClock replace the clock used for waiting, e.g. in tests.
+// It must be called before reading.
+func (l *RateLimitedReader) SetClock(c Clock) *RateLimitedReader {
+	l.tb.setClock(c)
+	return l
+}
+
+func (l *RateLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p[:l.tb.chunk(len(p))])
+	if werr := l.tb.wait(n); err == nil {
+		err = werr
+	}
+	return n, err
+}
+
+// WriteTo keep the fast path of underlying reader, while limiting the writes.
+func (l *RateLimitedReader) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := l.r.(io.WriterTo); ok {
+		return wt.WriteTo(&RateLimitedWriter{w: w, tb: l.tb})
+	}
+	return copyBuffer(w, onlyReader{l})
+}
+
+// RateLimitedWriter limit writing throughput to given bytes per second.
+type RateLimitedWriter struct {
+	w  io.Writer
+	tb *tokenBucket
+}
+
+// NewRateLimitedWriter wrap writer with token bucket limiter (bytes/second).
+// If rate <= 0, writing is not limited.
+func NewRateLimitedWriter(w io.Writer, rate int64) *RateLimitedWriter {
+	return &RateLimitedWriter{w: w, tb: newTokenBucket(context.Background(), rate)}
+}
+
+// SetClock replace the clock used for waiting, e.g. in tests.
+// It must be called before writing.
+func (l *RateLimitedWriter) SetClock(c Clock) *RateLimitedWriter {
+	l.tb.setClock(c)
+	return l
+}
+
+func (l *RateLimitedWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		sz := l.tb.chunk(len(p))
+		if err := l.tb.wait(sz); err != nil {
+			return written, err
+		}
+		n, err := l.w.Write(p[:sz])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[sz:]
+	}
+	return written, nil
+}
+
+// ReadFrom keep the fast path of underlying writer, while limiting the reads.
+func (l *RateLimitedWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := l.w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(&RateLimitedReader{r: r, tb: l.tb})
+	}
+	return copyBuffer(onlyWriter{l}, r)
+}
+
+// hide ReaderFrom/WriterTo to avoid recursion in io.Copy
+type onlyReader struct {
+	io.Reader
+}
+type onlyWriter struct {
+	io.Writer
+}
+
+// copyBuffer copy from src to dst using pooled buffer.
+func copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	buf := bytesPool.Get(copyBufferSize)
+	defer bytesPool.Put(buf)
+
+	return io.CopyBuffer(dst, src, buf)
+}
+
 // DevNull mimics /dev/null behaviour
 // It discard on write, and return EOF on read.
-var (
-	DevNull     = devNull{}
-	poolDevNull = NewBytesPool()
-)
+var DevNull = devNull{}
 
 type devNull struct{}
 
@@ -148,13 +421,13 @@ func (devNull) WriteTo(w io.Writer) (int64, error) {
 	return 0, io.EOF
 }
 func (devNull) ReadFrom(r io.Reader) (n int64, err error) {
-	bp := poolDevNull.Get().(*[]byte)
+	buf := bytesPool.Get(copyBufferSize)
 	szRd := 0
 	for {
-		szRd, err = r.Read(*bp)
+		szRd, err = r.Read(buf)
 		n += int64(szRd)
 		if err != nil {
-			poolDevNull.Put(bp)
+			bytesPool.Put(buf)
 			if errors.Is(err, io.EOF) {
 				return n, nil
 			}
@@ -169,6 +442,7 @@ func (devNull) Close() error {
 // NewBytesPool return pool with pre-allocated []byte
 // Arg `cap` is opitonal, and if not specified,
 // the capacity is 8192 bytes.
+// See NewSizedBytesPool for typed pool with size classes.
 func NewBytesPool(cap ...int) *sync.Pool {
 	nc := 8192
 	if len(cap) > 0 && cap[0] > 0 {
@@ -182,48 +456,183 @@ func NewBytesPool(cap ...int) *sync.Pool {
 	}
 }
 
-// ReadCloserFromDescriptor create reader with closer from given descriptor
+// ReadCloserFromDescriptor create reader with closer from given descriptor.
+// Descriptor is either special name (<stdin>, <null>), URL with registered scheme
+// (see RegisterDescriptorScheme) or filename.
+// Compressed content is decompressed transparently, where the compression
+// is determined from `compress` query or extension (.gz, .zst, .bz2, .xz).
+// Query `compress=auto` detect the compression from magic bytes.
+// Query `rate` (e.g. rate=1MB) limits reading throughput per second.
+// Options `compress` and `rate` are taken from the query of files and
+// built-in schemes, except http(s), tee and custom schemes.
 func ReadCloserFromDescriptor(desc string) (io.ReadCloser, error) {
-	ldesc := strings.ToLower(desc)
-	switch ldesc {
-	case IoEmpty, IoNull, IoDevNull:
-		return devNull{}, nil
-	case IoStdin:
-		return io.NopCloser(os.Stdin), nil
-	default:
-		return rwclFromDescriptor(desc, false)
+	return readCloserFromDescriptor(context.Background(), desc)
+}
+
+// ReadCloserFromDescriptorContext is like ReadCloserFromDescriptor, but the context
+// is used for opening (dial, listen, request) and pending Read is aborted
+// when the context is cancelled.
+func ReadCloserFromDescriptorContext(ctx context.Context, desc string) (io.ReadCloser, error) {
+	return readCloserFromDescriptor(ctx, desc)
+}
+
+func readCloserFromDescriptor(ctx context.Context, desc string) (io.ReadCloser, error) {
+	desc, opts, err := splitDescriptorOptions(desc)
+	if err != nil {
+		return nil, err
 	}
+	rwc, err := rwclFromDescriptor(ctx, desc, false)
+	if err != nil {
+		return nil, err
+	}
+	rc := asReadCloser(rwc)
+	if ctx.Done() != nil {
+		rc = NewContextReader(ctx, rc)
+	}
+	if opts.rate > 0 {
+		rc = readCloser{&RateLimitedReader{r: rc, tb: newTokenBucket(ctx, opts.rate)}, rc}
+	}
+	return decompressDescriptor(rc, opts.compress)
 }
 
 // WriteCloserFromDescriptor return io.WriteCloser from given descriptr.
-// Valid descriptor are: <null>, <stdout>, <stderr> and "desc" as filename
+// Valid descriptor are: <null>, <stdout>, <stderr>, URL with registered scheme
+// (see RegisterDescriptorScheme) and "desc" as filename.
+// Content is compressed when `compress` query is given or the descriptor
+// has compression extension (.gz, .zst, .bz2, .xz).
+// Query `rate` (e.g. rate=1MB) limits writing throughput per second.
+// Options `compress` and `rate` are taken from the query of files and
+// built-in schemes, except http(s), tee and custom schemes.
 func WriteCloserFromDescriptor(desc string) (io.WriteCloser, error) {
-	ldesc := strings.ToLower(desc)
-	switch ldesc {
-	case IoEmpty, IoNull, IoDevNull:
-		return devNull{}, nil
-	case IoStdout:
-		return NopWriteCloser(os.Stdout), nil
-	case IoStderr:
-		return NopWriteCloser(os.Stderr), nil
-	default:
-		return rwclFromDescriptor(desc, true)
+	return writeCloserFromDescriptor(context.Background(), desc)
+}
+
+// WriteCloserFromDescriptorContext is like WriteCloserFromDescriptor, but the context
+// is used for opening (dial, listen, request) and pending Write is aborted
+// when the context is cancelled.
+func WriteCloserFromDescriptorContext(ctx context.Context, desc string) (io.WriteCloser, error) {
+	return writeCloserFromDescriptor(ctx, desc)
+}
+
+func writeCloserFromDescriptor(ctx context.Context, desc string) (io.WriteCloser, error) {
+	desc, opts, err := splitDescriptorOptions(desc)
+	if err != nil {
+		return nil, err
 	}
+	rwc, err := rwclFromDescriptor(ctx, desc, true)
+	if err != nil {
+		return nil, err
+	}
+	wc := asWriteCloser(rwc)
+	if ctx.Done() != nil {
+		wc = NewContextWriter(ctx, wc)
+	}
+	if opts.rate > 0 {
+		wc = writeCloser{&RateLimitedWriter{w: wc, tb: newTokenBucket(ctx, opts.rate)}, wc}
+	}
+	return compressDescriptor(wc, opts.compress)
+}
+
+// descriptorOptions are generic options, given as descriptor query,
+// which are applied on top of any descriptor.
+type descriptorOptions struct {
+	// compress: compression name (see NewCompressWriter), default from extension
+	compress string
+	// rate: maximum transfer rate per second, e.g. 1MB
+	rate int64
+}
+
+// descriptorQueryOptions list schemes which accept generic options in the query.
+// Other schemes (e.g. http, custom schemes) receive the query as is.
+var descriptorQueryOptions = map[string][]string{
+	"":            {"compress", "rate"},
+	"file":        {"compress", "rate"},
+	"mem":         {"compress", "rate"},
+	"fd":          {"compress", "rate"},
+	"exec":        {"compress", "rate"},
+	"tail":        {"compress", "rate"},
+	"tcp":         {"compress", "rate"},
+	"tcp4":        {"compress", "rate"},
+	"tcp6":        {"compress", "rate"},
+	"udp":         {"compress", "rate"},
+	"udp4":        {"compress", "rate"},
+	"udp6":        {"compress", "rate"},
+	"unix":        {"compress", "rate"},
+	"unixgram":    {"compress", "rate"},
+	"tls":         {"compress", "rate"},
+	"tcp+tls":     {"compress", "rate"},
+	"tcp-listen":  {"compress", "rate"},
+	"tcp4-listen": {"compress", "rate"},
+	"tcp6-listen": {"compress", "rate"},
+	"unix-listen": {"compress", "rate"},
+	"rotate":      {"rate"},
+}
+
+// schemes which handle compression by themselves
+var descriptorOwnCompression = map[string]bool{
+	"rotate":   true,
+	"tee":      true,
+	"tee+drop": true,
+	"tee+best": true,
+}
+
+// splitDescriptorOptions return descriptor without generic options, and the options.
+// Compression is also determined from the extension, except for schemes
+// which handle compression by themselves.
+func splitDescriptorOptions(desc string) (string, *descriptorOptions, error) {
+	opts := descriptorOptions{}
+	u, err := url.Parse(desc)
+	if err != nil {
+		_, opts.compress = SplitCompressExt(desc)
+		return desc, &opts, nil
+	}
+	scheme := strings.ToLower(u.Scheme)
+	accepted := descriptorQueryOptions[scheme]
+	if !descriptorOwnCompression[scheme] {
+		_, opts.compress = SplitCompressExt(u.Path)
+	}
+	if len(accepted) == 0 {
+		return desc, &opts, nil
+	}
+
+	q := u.Query()
+	removed := false
+	if q.Has("compress") && slices.Contains(accepted, "compress") {
+		opts.compress = q.Get("compress")
+		q.Del("compress")
+		removed = true
+	}
+	if q.Has("rate") && slices.Contains(accepted, "rate") {
+		r := q.Get("rate")
+		n, ok := ToByteSize(r)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid rate: %s", r)
+		}
+		opts.rate = n
+		q.Del("rate")
+		removed = true
+	}
+	if removed {
+		// keep descriptor as is, only replace the query
+		desc, _, _ = strings.Cut(desc, "?")
+		if len(q) > 0 {
+			desc += "?" + q.Encode()
+		}
+	}
+	return desc, &opts, nil
 }
 
-func rwclFromDescriptor(desc string, wr bool) (io.ReadWriteCloser, error) {
-	// try parse
+func rwclFromDescriptor(ctx context.Context, desc string, wr bool) (io.ReadWriteCloser, error) {
+	// special names, e.g. <stdout>
+	if open, ok := specialDescriptors[strings.ToLower(desc)]; ok {
+		return open(ctx, &url.URL{Path: desc}, wr)
+	}
+
+	// create based on scheme
 	u, err := url.Parse(desc)
-	if err == nil {
-		// create based on scheme
-		scheme := strings.ToLower(u.Scheme)
-		switch scheme {
-		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
-			return net.Dial(scheme, u.Host)
-		case "unix", "unixgram":
-			return net.Dial(scheme, u.Path)
-		case "file":
-			desc = u.Path
+	if err == nil && u.Scheme != "" {
+		if open, err := descriptorSchemes.Get(strings.ToLower(u.Scheme)); err == nil {
+			return open(ctx, u, wr)
 		}
 	}
 
@@ -234,6 +643,22 @@ func rwclFromDescriptor(desc string, wr bool) (io.ReadWriteCloser, error) {
 	return os.Open(desc)
 }
 
+// asReadCloser unwrap read only descriptor
+func asReadCloser(rwc io.ReadWriteCloser) io.ReadCloser {
+	if ro, ok := rwc.(readOnlyRWCloser); ok {
+		return ro.ReadCloser
+	}
+	return rwc
+}
+
+// asWriteCloser unwrap write only descriptor
+func asWriteCloser(rwc io.ReadWriteCloser) io.WriteCloser {
+	if wo, ok := rwc.(writeOnlyRWCloser); ok {
+		return wo.WriteCloser
+	}
+	return rwc
+}
+
 // Closers hold list of io.Closer
 type Closers interface {
 	io.Closer
@@ -246,6 +671,56 @@ type Closers interface {
 	Clear()
 }
 
+// ContextClosers is Closers with configurable order, per item timeout
+// and context-aware Close.
+type ContextClosers interface {
+	Closers
+
+	// Defer append cleanup function to the list
+	Defer(fn func() error) ContextClosers
+	// SetOrder set closing order, default is CloseFIFO
+	SetOrder(order CloseOrder) ContextClosers
+	// SetTimeout set maximum duration of each Close, zero means no timeout
+	SetTimeout(d time.Duration) ContextClosers
+	// CloseContext close all items, abandoning pending Close when
+	// context is done. Items which are not closed yet are kept.
+	CloseContext(ctx context.Context) error
+}
+
+// CloseOrder is order of closing items in Closers
+type CloseOrder int
+
+const (
+	// CloseFIFO close items in insertion order
+	CloseFIFO CloseOrder = iota
+	// CloseLIFO close items in reverse order, like defer
+	CloseLIFO
+)
+
+// ErrCloseTimeout returned when Close does not finish within timeout
+var ErrCloseTimeout = errors.New("close timeout")
+
+// CloserError is error returned by Closers, naming the failing item.
+type CloserError struct {
+	Index  int
+	Closer io.Closer
+	Err    error
+}
+
+func (e *CloserError) Error() string {
+	return fmt.Sprintf("closer #%d (%T): %v", e.Index, e.Closer, e.Err)
+}
+func (e *CloserError) Unwrap() error {
+	return e.Err
+}
+
+// CloserFunc adapt function to io.Closer
+type CloserFunc func() error
+
+func (f CloserFunc) Close() error {
+	return f()
+}
+
 type safeCloser struct {
 	c io.Closer
 }
@@ -268,7 +743,7 @@ type safeSyncCloser struct {
 }
 
 func SafeSyncCloser(c io.Closer) io.Closer {
-	return &safeCloser{c: c}
+	return &safeSyncCloser{c: c}
 }
 
 func (sc *safeSyncCloser) Close() error {
@@ -284,13 +759,20 @@ func (sc *safeSyncCloser) Close() error {
 
 type closers struct {
 	sync.RWMutex
-	items []io.Closer
+	items   []io.Closer
+	order   CloseOrder
+	timeout time.Duration
 }
 
 func NewClosers(c ...io.Closer) Closers {
 	return &closers{items: c}
 }
 
+// NewContextClosers create ContextClosers with given items.
+func NewContextClosers(c ...io.Closer) ContextClosers {
+	return &closers{items: c}
+}
+
 func (cs *closers) Empty() bool {
 	cs.RLock()
 	defer cs.RUnlock()
@@ -304,19 +786,78 @@ func (cs *closers) Len() int {
 	return len(cs.items)
 }
 func (cs *closers) Close() error {
+	return cs.CloseContext(context.Background())
+}
+func (cs *closers) CloseContext(ctx context.Context) error {
 	cs.Lock()
 	defer cs.Unlock()
 
+	n := len(cs.items)
 	var errs error
-	for _, c := range cs.items {
-		if c != nil {
-			errs = errors.Join(errs, c.Close())
+	for i := range n {
+		idx := i
+		if cs.order == CloseLIFO {
+			idx = n - 1 - i
+		}
+		if c := cs.items[idx]; c != nil {
+			if err := cs.closeItem(ctx, c); err != nil {
+				errs = errors.Join(errs, &CloserError{Index: idx, Closer: c, Err: err})
+			}
+		}
+		if ctx.Err() != nil {
+			// keep remaining items
+			if cs.order == CloseLIFO {
+				cs.items = cs.items[:idx]
+			} else {
+				cs.items = cs.items[idx+1:]
+			}
+			return errs
 		}
 	}
 	cs.items = nil
 
 	return errs
 }
+
+// closeItem close single item, waiting at most timeout or until context is done.
+func (cs *closers) closeItem(ctx context.Context, c io.Closer) error {
+	if cs.timeout <= 0 && ctx.Done() == nil {
+		return c.Close()
+	}
+	if cs.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, cs.timeout, ErrCloseTimeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+func (cs *closers) Defer(fn func() error) ContextClosers {
+	cs.Append(CloserFunc(fn))
+	return cs
+}
+func (cs *closers) SetOrder(order CloseOrder) ContextClosers {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.order = order
+	return cs
+}
+func (cs *closers) SetTimeout(d time.Duration) ContextClosers {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.timeout = d
+	return cs
+}
 func (cs *closers) Append(c io.Closer) Closers {
 	cs.Lock()
 	defer cs.Unlock()
@@ -0,0 +1,188 @@
+package pola
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	CompressNone  = "none"
+	CompressAuto  = "auto"
+	CompressGzip  = "gzip"
+	CompressZstd  = "zstd"
+	CompressBzip2 = "bzip2"
+	CompressXz    = "xz"
+)
+
+var (
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+
+	compressExts = map[string]string{
+		".gz":   CompressGzip,
+		".gzip": CompressGzip,
+		".zst":  CompressZstd,
+		".zstd": CompressZstd,
+		".bz2":  CompressBzip2,
+		".xz":   CompressXz,
+	}
+	compressMagics = []struct {
+		magic       []byte
+		compression string
+	}{
+		{[]byte{0x1f, 0x8b}, CompressGzip},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd}, CompressZstd},
+		{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, CompressXz},
+	}
+
+	// bzip2 header "BZh[1-9]" is followed by block magic (pi)
+	// or end-of-stream magic (sqrt(pi)) for empty stream
+	bzip2Blocks = [][]byte{
+		{0x31, 0x41, 0x59, 0x26, 0x53, 0x59},
+		{0x17, 0x72, 0x45, 0x38, 0x50, 0x90},
+	}
+)
+
+// SplitCompressExt split compression extension from the name,
+// e.g. "data.yaml.gz" returns ("data.yaml", "gzip").
+// If name has no known compression extension, the compression is empty.
+func SplitCompressExt(name string) (string, string) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if c, ok := compressExts[ext]; ok {
+		return name[:len(name)-len(ext)], c
+	}
+	return name, ""
+}
+
+// DetectCompression peek the beginning of the stream and detect compression
+// based on its magic bytes. The returned reader must be used instead of `r`.
+func DetectCompression(r io.Reader) (io.Reader, string) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(10)
+	for _, m := range compressMagics {
+		if bytes.HasPrefix(head, m.magic) {
+			return br, m.compression
+		}
+	}
+	if isBzip2(head) {
+		return br, CompressBzip2
+	}
+	return br, ""
+}
+
+func isBzip2(head []byte) bool {
+	if len(head) < 10 || !bytes.HasPrefix(head, []byte("BZh")) || head[3] < '1' || head[3] > '9' {
+		return false
+	}
+	for _, m := range bzip2Blocks {
+		if bytes.Equal(head[4:10], m) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDecompressReader wrap `r` with decompressor for given compression.
+// Compression `auto` detect the compression from magic bytes,
+// while empty or `none` return `r` as is.
+func NewDecompressReader(r io.Reader, compression string) (io.ReadCloser, error) {
+	compression = strings.ToLower(compression)
+	if compression == CompressAuto {
+		r, compression = DetectCompression(r)
+	}
+	switch compression {
+	case "", CompressNone:
+		return io.NopCloser(r), nil
+	case CompressGzip:
+		return gzip.NewReader(r)
+	case CompressZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case CompressBzip2:
+		return bzip2.NewReader(r, nil)
+	case CompressXz:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+// NewCompressWriter wrap `w` with compressor for given compression.
+// Closing returned writer flush the compressed stream, but does not close `w`.
+func NewCompressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch strings.ToLower(compression) {
+	case "", CompressNone:
+		return NopWriteCloser(w), nil
+	case CompressGzip:
+		return gzip.NewWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w)
+	case CompressBzip2:
+		return bzip2.NewWriter(w, nil)
+	case CompressXz:
+		return xz.NewWriter(w)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, compression)
+}
+
+// decompressDescriptor wrap reader opened from descriptor with decompressor.
+// Without compression, the reader is returned as is.
+func decompressDescriptor(rc io.ReadCloser, compression string) (io.ReadCloser, error) {
+	if compression == "" || strings.EqualFold(compression, CompressNone) {
+		return rc, nil
+	}
+	dr, err := NewDecompressReader(rc, compression)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &compressedReadCloser{ReadCloser: dr, under: rc}, nil
+}
+
+// compressDescriptor wrap writer opened from descriptor with compressor.
+func compressDescriptor(wc io.WriteCloser, compression string) (io.WriteCloser, error) {
+	if compression == "" || strings.EqualFold(compression, CompressNone) {
+		return wc, nil
+	}
+	cw, err := NewCompressWriter(wc, compression)
+	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+	return &compressedWriteCloser{WriteCloser: cw, under: wc}, nil
+}
+
+// compressedReadCloser close decompressor and underlying reader
+type compressedReadCloser struct {
+	io.ReadCloser
+	under io.Closer
+}
+
+func (c *compressedReadCloser) Close() error {
+	return errors.Join(c.ReadCloser.Close(), c.under.Close())
+}
+
+// compressedWriteCloser flush compressor and close underlying writer
+type compressedWriteCloser struct {
+	io.WriteCloser
+	under io.Closer
+}
+
+func (c *compressedWriteCloser) Close() error {
+	return errors.Join(c.WriteCloser.Close(), c.under.Close())
+}
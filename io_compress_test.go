@@ -0,0 +1,91 @@
+package pola_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	content := "debug: true\nitems:\n" + strings.Repeat("  - compressed item\n", 100)
+	names := []string{"data.yaml.gz", "data.yaml.zst", "data.yaml.bz2", "data.yaml.xz"}
+	for _, name := range names {
+		pth := filepath.Join(dir, name)
+		w, err := pola.WriteCloserFromDescriptor(pth)
+		assert.NoError(t, err, name)
+		io.WriteString(w, content)
+		assert.NoError(t, w.Close(), name)
+
+		raw, _ := os.ReadFile(pth)
+		assert.Less(t, len(raw), len(content), name)
+
+		r, err := pola.ReadCloserFromDescriptor("file://" + pth)
+		assert.NoError(t, err, name)
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err, name)
+		assert.NoError(t, r.Close(), name)
+		assert.Equal(t, content, string(data), name)
+
+		// compose with decoder
+		var dst map[string]any
+		assert.NoError(t, pola.NewFsDecoder(pth).Decode(&dst), name)
+		assert.Equal(t, true, dst["debug"], name)
+
+		// magic bytes detection
+		plain := filepath.Join(dir, "renamed")
+		assert.NoError(t, os.Rename(pth, plain))
+		r, err = pola.ReadCloserFromDescriptor(plain + "?compress=auto")
+		assert.NoError(t, err, name)
+		data, _ = io.ReadAll(r)
+		r.Close()
+		assert.Equal(t, content, string(data), name)
+	}
+
+	// forced by query
+	pth := filepath.Join(dir, "forced.out")
+	w, err := pola.WriteCloserFromDescriptor("file://" + pth + "?compress=zstd")
+	assert.NoError(t, err)
+	io.WriteString(w, content)
+	assert.NoError(t, w.Close())
+
+	r, err := pola.ReadCloserFromDescriptor("file://" + pth + "?compress=none")
+	assert.NoError(t, err)
+	raw, _ := io.ReadAll(r)
+	r.Close()
+	assert.NotEqual(t, content, string(raw))
+
+	var dst map[string]any
+	assert.NoError(t, pola.NewBytesDecoder(raw, ".yaml.zst").Decode(&dst))
+
+	_, err = pola.WriteCloserFromDescriptor("file://" + pth + "?compress=lz4")
+	assert.ErrorIs(t, err, pola.ErrUnsupportedCompression)
+}
+
+func TestPlainDescriptorNotDetected(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "notes.txt")
+	content := "BZh is how the bzip2 header starts\n"
+	assert.NoError(t, os.WriteFile(pth, []byte(content), 0644))
+
+	// plain file is not wrapped
+	r, err := pola.ReadCloserFromDescriptor(pth)
+	assert.NoError(t, err)
+	assert.IsType(t, &os.File{}, r)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, content, string(data))
+
+	// auto detection requires full bzip2 magic
+	r, err = pola.ReadCloserFromDescriptor(pth + "?compress=auto")
+	assert.NoError(t, err)
+	data, err = io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, content, string(data))
+}
@@ -0,0 +1,138 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ContextReader abort pending Read when context is cancelled.
+// Blocked Read is unblocked by setting read deadline (net.Conn, pipes),
+// otherwise the underlying reader is closed (if it implements io.Closer).
+type ContextReader struct {
+	ctx  context.Context
+	r    io.Reader
+	stop func() bool
+}
+
+// NewContextReader wrap reader `r` with given context.
+func NewContextReader(ctx context.Context, r io.Reader) *ContextReader {
+	return &ContextReader{
+		ctx: ctx,
+		r:   r,
+		stop: context.AfterFunc(ctx, func() {
+			if !expireDeadline(r, func(d deadliner) error { return d.SetReadDeadline(aLongTimeAgo) }) {
+				if cl, ok := r.(io.Closer); ok {
+					cl.Close()
+				}
+			}
+		}),
+	}
+}
+
+func (c *ContextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := c.r.Read(p)
+	if err != nil && c.ctx.Err() != nil {
+		err = c.ctx.Err()
+	}
+	return n, err
+}
+
+// Close stop watching the context and close underlying reader, if any.
+func (c *ContextReader) Close() error {
+	c.stop()
+	if cl, ok := c.r.(io.Closer); ok {
+		return cl.Close()
+	}
+	return nil
+}
+
+// ContextWriter abort pending Write when context is cancelled.
+// Blocked Write is unblocked by setting write deadline (net.Conn, pipes).
+// Otherwise the underlying writer is aborted with `Abort() error` method
+// (e.g. atomic file discard its content), `CloseWithError` (io.PipeWriter)
+// or closed if it is *os.File. Other writers are never closed on cancel,
+// since their Close may commit partial content.
+type ContextWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	stop func() bool
+}
+
+// NewContextWriter wrap writer `w` with given context.
+func NewContextWriter(ctx context.Context, w io.Writer) *ContextWriter {
+	return &ContextWriter{
+		ctx: ctx,
+		w:   w,
+		stop: context.AfterFunc(ctx, func() {
+			if !expireDeadline(w, func(d deadliner) error { return d.SetWriteDeadline(aLongTimeAgo) }) {
+				abortWriter(ctx, w)
+			}
+		}),
+	}
+}
+
+func (c *ContextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := c.w.Write(p)
+	if err != nil && c.ctx.Err() != nil {
+		err = c.ctx.Err()
+	}
+	return n, err
+}
+
+// Close stop watching the context and close underlying writer, if any.
+// If the context is cancelled, writer with `Abort() error` method is aborted
+// instead of committed, and the context error is returned as well.
+func (c *ContextWriter) Close() error {
+	c.stop()
+	if a, ok := c.w.(aborter); ok && c.ctx.Err() != nil {
+		a.Abort()
+	}
+	var err error
+	if cl, ok := c.w.(io.Closer); ok {
+		err = cl.Close()
+	}
+	return errors.Join(c.ctx.Err(), err)
+}
+
+// non-zero time in the past, used to expire deadline immediately
+var aLongTimeAgo = time.Unix(1, 0)
+
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// expireDeadline return true if deadline is supported and expired.
+func expireDeadline(v any, expire func(d deadliner) error) bool {
+	d, ok := v.(deadliner)
+	return ok && expire(d) == nil
+}
+
+type aborter interface {
+	Abort() error
+}
+
+type closeWithErrorer interface {
+	CloseWithError(err error) error
+}
+
+// abortWriter unblock pending Write without committing the content.
+func abortWriter(ctx context.Context, w io.Writer) {
+	switch x := w.(type) {
+	case aborter:
+		x.Abort()
+	case closeWithErrorer:
+		x.CloseWithError(ctx.Err())
+	case *os.File:
+		x.Close()
+	}
+}
@@ -0,0 +1,140 @@
+package pola_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextReaderWriter(t *testing.T) {
+	// deadline based abort
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := pola.NewContextReader(ctx, c1)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := r.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, r.Close())
+
+	c1, c2 = net.Pipe()
+	defer c1.Close()
+	ctx, cancel = context.WithCancel(context.Background())
+	w := pola.NewContextWriter(ctx, c2)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = w.Write([]byte("blocked"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, w.Close(), context.Canceled)
+
+	// close based abort
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ctx, cancel = context.WithCancel(context.Background())
+	r = pola.NewContextReader(ctx, pr)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = r.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// not cancelled
+	pr, pw = io.Pipe()
+	r = pola.NewContextReader(context.Background(), pr)
+	go func() {
+		io.WriteString(pw, "hello")
+		pw.Close()
+	}()
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestDescriptorContext(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "ctx.sock")
+
+	// pending accept
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := pola.ReadCloserFromDescriptorContext(ctx, "unix-listen://"+sock)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// pending read
+	ln, err := net.Listen("unix", sock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	r, err := pola.ReadCloserFromDescriptorContext(ctx, "unix://"+sock)
+	if !assert.NoError(t, err) {
+		return
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, r.Close())
+
+	// regular file is still readable
+	pth := filepath.Join(dir, "x.txt")
+	assert.NoError(t, writeDescriptor(t, pth, "content"))
+	r, err = pola.ReadCloserFromDescriptorContext(context.Background(), pth)
+	if assert.NoError(t, err) {
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+		assert.NoError(t, r.Close())
+	}
+
+	w, err := pola.WriteCloserFromDescriptorContext(ctx, pth)
+	if assert.NoError(t, err) {
+		_, err = io.WriteString(w, "x")
+		assert.ErrorIs(t, err, context.Canceled)
+		w.Close()
+	}
+}
+
+func TestAtomicFileContext(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(pth, []byte("GOOD CONFIG"), 0o644))
+
+	// cancel must not replace the target with partial content
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := pola.WriteCloserFromDescriptorContext(ctx, "file://"+pth+"?atomic=1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = io.WriteString(w, "PARTIAL")
+	assert.NoError(t, err)
+	cancel()
+	assert.ErrorIs(t, w.Close(), context.Canceled)
+
+	data, err := os.ReadFile(pth)
+	assert.NoError(t, err)
+	assert.Equal(t, "GOOD CONFIG", string(data))
+	entries, _ := os.ReadDir(filepath.Dir(pth))
+	assert.Len(t, entries, 1)
+
+	// not canceled
+	w, err = pola.WriteCloserFromDescriptorContext(context.Background(), "file://"+pth+"?atomic=1")
+	if assert.NoError(t, err) {
+		io.WriteString(w, "NEW CONFIG")
+		assert.NoError(t, w.Close())
+	}
+	data, _ = os.ReadFile(pth)
+	assert.Equal(t, "NEW CONFIG", string(data))
+}
@@ -0,0 +1,150 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// stderrTailSize is the number of stderr bytes kept for error reporting
+	stderrTailSize = 4096
+	// execWaitDelay bound the wait for pipes held by descendant processes
+	execWaitDelay = 500 * time.Millisecond
+)
+
+// tailBuffer keep the last `size` bytes written into it
+type tailBuffer struct {
+	sync.Mutex
+	size int
+	data []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.data = append(t.data, p...)
+	if over := len(t.data) - t.size; over > 0 {
+		t.data = t.data[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.Lock()
+	defer t.Unlock()
+
+	return strings.TrimSpace(string(t.data))
+}
+
+// ExecError is returned when process started from exec:// descriptor fails.
+type ExecError struct {
+	Path   string
+	Err    error
+	Stderr string
+}
+
+func (e *ExecError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("exec %s: %v, stderr: %s", e.Path, e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("exec %s: %v", e.Path, e.Err)
+}
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// execFromDescriptor start process, e.g. exec:///usr/bin/jq?arg=.items&arg=-c.
+// Program is taken from the path (or host for relative name, e.g. exec://jq),
+// arguments from repeated `arg` query, and optional `dir` set working directory.
+// Reading stream the process stdout, writing pipe data into its stdin.
+// Close wait for the process and report non-zero exit status with stderr tail.
+// The process is started in its own process group (unix), and the whole group
+// is killed when the context is canceled.
+func execFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	name := u.Host + u.Path
+	if name == "" {
+		return nil, errors.New("exec descriptor without program")
+	}
+	q := u.Query()
+	cmd := exec.CommandContext(ctx, name, q["arg"]...)
+	cmd.Dir = q.Get("dir")
+	cmd.WaitDelay = execWaitDelay
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcess(cmd)
+	}
+
+	stderr := &tailBuffer{size: stderrTailSize}
+	cmd.Stderr = stderr
+	ep := &execProcess{cmd: cmd, stderr: stderr}
+
+	var err error
+	if wr {
+		if ep.stdin, err = cmd.StdinPipe(); err != nil {
+			return nil, err
+		}
+	} else {
+		if ep.stdout, err = cmd.StdoutPipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, &ExecError{Path: name, Err: err}
+	}
+
+	if wr {
+		return writeOnlyRWCloser{ep}, nil
+	}
+	return readOnlyRWCloser{ep}, nil
+}
+
+type execProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr *tailBuffer
+	eof    bool
+	once   sync.Once
+	err    error
+}
+
+func (e *execProcess) Read(p []byte) (int, error) {
+	n, err := e.stdout.Read(p)
+	if err == io.EOF {
+		e.eof = true
+	}
+	return n, err
+}
+
+func (e *execProcess) Write(p []byte) (int, error) {
+	return e.stdin.Write(p)
+}
+
+// Close close stdin (signal end of input) and wait for the process.
+// If the reader is closed before stdout is fully consumed,
+// the process is killed and its exit status is not reported.
+func (e *execProcess) Close() error {
+	e.once.Do(func() {
+		if e.stdin != nil {
+			e.stdin.Close()
+		}
+		if e.stdout != nil && !e.eof {
+			e.stdout.Close()
+			killProcess(e.cmd)
+			e.cmd.Wait()
+			return
+		}
+		if err := e.cmd.Wait(); err != nil {
+			e.err = &ExecError{Path: e.cmd.Path, Err: err, Stderr: e.stderr.String()}
+		}
+	})
+	return e.err
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package pola
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcess kill started process.
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
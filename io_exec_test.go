@@ -0,0 +1,78 @@
+//go:build unix
+
+package pola_test
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecDescriptor(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+
+	r, err := pola.ReadCloserFromDescriptor("exec://" + sh + "?arg=-c&arg=echo+hello")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+	assert.NoError(t, r.Close())
+
+	// stdin is piped, non-zero exit status is reported with stderr
+	w, err := pola.WriteCloserFromDescriptor("exec://" + sh + "?arg=-c&arg=cat+>/dev/null%3B+echo+failed+>%262%3B+exit+3")
+	assert.NoError(t, err)
+	io.WriteString(w, "data")
+	err = w.Close()
+	var ee *pola.ExecError
+	if assert.ErrorAs(t, err, &ee) {
+		assert.Equal(t, "failed", ee.Stderr)
+	}
+	var exitErr *exec.ExitError
+	if assert.ErrorAs(t, err, &exitErr) {
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+
+	// closing before output is consumed kill the process
+	r, err = pola.ReadCloserFromDescriptor("exec://" + sh + "?arg=-c&arg=yes")
+	assert.NoError(t, err)
+	r.Read(make([]byte, 10))
+	assert.NoError(t, r.Close())
+
+	_, err = pola.ReadCloserFromDescriptor("exec:///does/not/exist")
+	assert.Error(t, err)
+}
+
+func TestExecDescriptorCancel(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+
+	// sleep runs as grandchild, holding stdout and stderr pipes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := pola.ReadCloserFromDescriptorContext(ctx, "exec://"+sh+"?arg=-c&arg=sleep+10%3B+true")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(r)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	r.Close()
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package pola
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup start the process in its own group,
+// so the whole group (e.g. shell pipeline) can be killed.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcess kill the process group of started process.
+func killProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,174 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// fileOptions are taken from `file://` descriptor query
+type fileOptions struct {
+	append bool
+	atomic bool
+	mkdir  bool
+	mode   os.FileMode
+}
+
+func parseFileOptions(q url.Values) (*fileOptions, error) {
+	opts := fileOptions{
+		append: ToBool(q.Get("append")),
+		atomic: ToBool(q.Get("atomic")),
+		mkdir:  ToBool(q.Get("mkdir")),
+		mode:   0666,
+	}
+	if m := q.Get("mode"); m != "" {
+		v, err := strconv.ParseUint(m, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file mode: %s", m)
+		}
+		opts.mode = os.FileMode(v)
+	}
+	if opts.append && opts.atomic {
+		return nil, errors.New("file options `append` and `atomic` can not be combined")
+	}
+	return &opts, nil
+}
+
+// openFileDescriptor open file for reading, or create file for writing.
+// Supported query options for writing are:
+//   - append=1: append to existing file instead of truncating it
+//   - mode=0640: permission (octal) of created file
+//   - mkdir=1: create parent directories
+//   - atomic=1: write into temporary file, which is renamed to the target on Close
+//
+// For reading, `follow=1` follow the file as it grows (see tailFromDescriptor).
+func openFileDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	name := u.Path
+	if !wr {
+		if ToBool(u.Query().Get("follow")) {
+			return tailFromDescriptor(ctx, u, wr)
+		}
+		return os.Open(name)
+	}
+	opts, err := parseFileOptions(u.Query())
+	if err != nil {
+		return nil, err
+	}
+	if opts.mkdir {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return nil, err
+		}
+	}
+	if opts.atomic {
+		return createAtomicFile(name, opts.mode)
+	}
+	flag := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if opts.append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(name, flag, opts.mode)
+}
+
+// atomicFile write to temporary file in the same directory
+// and rename it to the target name on Close.
+// If any write fails (or Abort is called), the target is left untouched.
+type atomicFile struct {
+	mu      sync.Mutex
+	f       *os.File
+	name    string
+	err     error
+	closed  bool
+	aborted bool
+}
+
+// createAtomicFile create temporary file next to `name`. The file is created
+// with `mode` (subject to umask), as plain os.OpenFile does.
+func createAtomicFile(name string, mode os.FileMode) (*atomicFile, error) {
+	prefix := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+	for range 100 {
+		tmp := prefix + strconv.FormatUint(rand.Uint64(), 36)
+		f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_EXCL, mode)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &atomicFile{f: f, name: name}, nil
+	}
+	return nil, fmt.Errorf("create temporary file for %s: %w", name, fs.ErrExist)
+}
+
+func (a *atomicFile) Write(p []byte) (int, error) {
+	n, err := a.f.Write(p)
+	if err != nil {
+		a.mu.Lock()
+		if a.err == nil {
+			a.err = err
+		}
+		a.mu.Unlock()
+	}
+	return n, err
+}
+
+// Read is not supported, atomic file is write only
+func (a *atomicFile) Read(p []byte) (int, error) {
+	return 0, ErrNotReadable
+}
+
+// Abort discard temporary file without touching the target.
+// Subsequent Close does nothing.
+func (a *atomicFile) Abort() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.aborted = true
+	return a.discard()
+}
+
+// discard must be called with lock held.
+func (a *atomicFile) discard() error {
+	a.closed = true
+	tmp := a.f.Name()
+	return errors.Join(a.f.Close(), os.Remove(tmp))
+}
+
+// Close rename temporary file to the target, unless a write failed.
+func (a *atomicFile) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.aborted {
+		return nil
+	}
+	if a.closed {
+		return os.ErrClosed
+	}
+	if a.err != nil {
+		err := a.discard()
+		return errors.Join(fmt.Errorf("atomic write %s aborted: %w", a.name, a.err), err)
+	}
+	a.closed = true
+
+	tmp := a.f.Name()
+	err := a.f.Sync()
+	err = errors.Join(err, a.f.Close())
+	if err == nil {
+		err = os.Rename(tmp, a.name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package pola_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeDescriptor(t *testing.T, desc, content string) error {
+	w, err := pola.WriteCloserFromDescriptor(desc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	assert.NoError(t, err)
+	return w.Close()
+}
+
+func TestFileDescriptorOptions(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "sub", "dir", "x.log")
+
+	// parent directory does not exist
+	assert.Error(t, writeDescriptor(t, "file://"+pth, "a"))
+	assert.NoError(t, writeDescriptor(t, "file://"+pth+"?mkdir=1&mode=0640", "first\n"))
+	assert.NoError(t, writeDescriptor(t, "file://"+pth+"?append=1", "second\n"))
+
+	data, err := os.ReadFile(pth)
+	assert.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+	fi, err := os.Stat(pth)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+
+	// atomic write only replace the target on Close
+	w, err := pola.WriteCloserFromDescriptor("file://" + pth + "?atomic=1&mode=0600")
+	assert.NoError(t, err)
+	io.WriteString(w, "replaced\n")
+	data, _ = os.ReadFile(pth)
+	assert.Equal(t, "first\nsecond\n", string(data))
+	assert.NoError(t, w.Close())
+	data, _ = os.ReadFile(pth)
+	assert.Equal(t, "replaced\n", string(data))
+	assert.Error(t, w.Close())
+
+	entries, _ := os.ReadDir(filepath.Dir(pth))
+	assert.Len(t, entries, 1)
+
+	assert.Error(t, writeDescriptor(t, "file://"+pth+"?append=1&atomic=1", "x"))
+	assert.Error(t, writeDescriptor(t, "file://"+pth+"?mode=abc", "x"))
+}
+
+func TestAtomicFileMode(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	atomic := filepath.Join(dir, "atomic.txt")
+
+	// default mode is subject to umask, as plain write
+	assert.NoError(t, writeDescriptor(t, plain, "x"))
+	assert.NoError(t, writeDescriptor(t, "file://"+atomic+"?atomic=1", "x"))
+	fp, err := os.Stat(plain)
+	assert.NoError(t, err)
+	fa, err := os.Stat(atomic)
+	assert.NoError(t, err)
+	assert.Equal(t, fp.Mode().Perm(), fa.Mode().Perm())
+}
@@ -0,0 +1,148 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrHTTPStatus = errors.New("unexpected http status")
+)
+
+// httpOptions are taken from (and removed from) descriptor query
+type httpOptions struct {
+	method  string
+	timeout time.Duration
+	header  http.Header
+}
+
+// parseHTTPDescriptor extract `method`, `timeout` and `header` (repeatable, "Name: value")
+// query parameters. The remaining query is sent to the server.
+func parseHTTPDescriptor(u *url.URL, wr bool) (*url.URL, *httpOptions, error) {
+	opts := httpOptions{
+		method: http.MethodGet,
+		header: make(http.Header),
+	}
+	if wr {
+		opts.method = http.MethodPost
+	}
+
+	q := u.Query()
+	if m := q.Get("method"); m != "" {
+		opts.method = strings.ToUpper(m)
+	}
+	if t := q.Get("timeout"); t != "" {
+		d, ok := ToDuration(t)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid timeout: %s", t)
+		}
+		opts.timeout = d
+	}
+	for _, h := range q["header"] {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid header: %s", h)
+		}
+		opts.header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	// keep the query as is, unless an option is removed
+	ru := *u
+	if q.Has("method") || q.Has("timeout") || q.Has("header") {
+		q.Del("method")
+		q.Del("timeout")
+		q.Del("header")
+		ru.RawQuery = q.Encode()
+	}
+	return &ru, &opts, nil
+}
+
+func checkHTTPResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	// include beginning of the body for diagnostic
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	if len(msg) > 0 {
+		return fmt.Errorf("%w: %s, %s", ErrHTTPStatus, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return fmt.Errorf("%w: %s", ErrHTTPStatus, resp.Status)
+}
+
+// httpFromDescriptor stream response body (read) or request body (write).
+func httpFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	ru, opts, err := parseHTTPDescriptor(u, wr)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: opts.timeout}
+
+	if !wr {
+		req, err := http.NewRequestWithContext(ctx, opts.method, ru.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header = opts.header
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkHTTPResponse(resp); err != nil {
+			return nil, err
+		}
+		return readOnlyRWCloser{resp.Body}, nil
+	}
+
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, opts.method, ru.String(), pr)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = opts.header
+
+	hw := &httpWriter{pw: pw, chErr: make(chan error, 1)}
+	go func() {
+		resp, err := client.Do(req)
+		if err == nil {
+			if err = checkHTTPResponse(resp); err == nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}
+		// unblock writer if request terminated early
+		pr.CloseWithError(errors.Join(err, io.ErrClosedPipe))
+		hw.chErr <- err
+	}()
+
+	return writeOnlyRWCloser{hw}, nil
+}
+
+// httpWriter stream written data as request body
+type httpWriter struct {
+	pw    *io.PipeWriter
+	chErr chan error
+	err   error
+	done  bool
+}
+
+func (h *httpWriter) Write(p []byte) (int, error) {
+	return h.pw.Write(p)
+}
+
+// Close finish the request and return error if the request failed
+// or the response status is not 2xx.
+func (h *httpWriter) Close() error {
+	if !h.done {
+		h.done = true
+		h.pw.Close()
+		h.err = <-h.chErr
+	}
+	return h.err
+}
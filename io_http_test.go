@@ -0,0 +1,65 @@
+package pola_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPDescriptor(t *testing.T) {
+	var received []byte
+	var method, auth, query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		query = r.URL.RawQuery
+		switch r.URL.Path {
+		case "/data":
+			io.WriteString(w, `{"debug": true}`)
+		case "/upload":
+			received, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.Error(w, "not here", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := pola.ReadCloserFromDescriptor(srv.URL + "/data?header=Authorization:+Bearer+abc&timeout=5s&page=2")
+	assert.NoError(t, err)
+	var dst map[string]any
+	assert.NoError(t, pola.NewDecoder(r, pola.ExtJson).Decode(&dst))
+	assert.NoError(t, r.Close())
+	assert.Equal(t, true, dst["debug"])
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "Bearer abc", auth)
+	assert.Equal(t, "page=2", query)
+
+	// query without options is sent as is
+	r, err = pola.ReadCloserFromDescriptor(srv.URL + "/data?z=1&a=%7e&b")
+	if assert.NoError(t, err) {
+		assert.NoError(t, r.Close())
+	}
+	assert.Equal(t, "z=1&a=%7e&b", query)
+
+	_, err = pola.ReadCloserFromDescriptor(srv.URL + "/missing")
+	assert.ErrorIs(t, err, pola.ErrHTTPStatus)
+	assert.ErrorContains(t, err, "not here")
+
+	w, err := pola.WriteCloserFromDescriptor(srv.URL + "/upload?method=put")
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, "payload")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "payload", string(received))
+
+	w, err = pola.WriteCloserFromDescriptor(srv.URL + "/missing")
+	assert.NoError(t, err)
+	io.WriteString(w, "payload")
+	assert.ErrorIs(t, w.Close(), pola.ErrHTTPStatus)
+}
@@ -0,0 +1,173 @@
+package pola
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// dialFromDescriptor connect to tcp://host:port, udp://host:port or unix:///path
+// With `reconnect=1` query, writer redial when the connection is lost (see ReconnectWriter).
+func dialFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	d := net.Dialer{}
+	network := strings.ToLower(u.Scheme)
+	addr := u.Host
+	if strings.HasPrefix(network, "unix") {
+		addr = u.Path
+	}
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return d.DialContext(ctx, network, addr)
+	}
+	if wr && ToBool(u.Query().Get("reconnect")) {
+		return reconnectFromDescriptor(ctx, u, dial)
+	}
+	return dial(ctx)
+}
+
+// listenFromDescriptor listen on given address and return the first accepted connection.
+// When reading with `fanin=1` query, all accepted connections are merged
+// line by line into a single reader.
+func listenFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	network := strings.TrimSuffix(strings.ToLower(u.Scheme), "-listen")
+	addr := u.Host
+	if network == "unix" {
+		addr = u.Path
+	}
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !wr && ToBool(u.Query().Get("fanin")) {
+		return newFanInReader(ln), nil
+	}
+
+	// unblock Accept when context is cancelled
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	conn, err := ln.Accept()
+	if !stop() || err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		ln.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return &listenConn{Conn: conn, ln: ln}, nil
+}
+
+// listenConn close the listener together with the connection
+type listenConn struct {
+	net.Conn
+	ln net.Listener
+}
+
+func (c *listenConn) Close() error {
+	err := c.Conn.Close()
+	if lerr := c.ln.Close(); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+// fanInReader accept every incoming connection and merge
+// their content line by line, so lines from different
+// connections are not interleaved.
+type fanInReader struct {
+	sync.Mutex
+	wmu    sync.Mutex
+	closed bool
+	ln     net.Listener
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+}
+
+func newFanInReader(ln net.Listener) io.ReadWriteCloser {
+	pr, pw := io.Pipe()
+	f := &fanInReader{
+		ln:    ln,
+		pr:    pr,
+		pw:    pw,
+		conns: make(map[net.Conn]struct{}),
+	}
+	f.wg.Add(1)
+	go f.accept()
+
+	return readOnlyRWCloser{f}
+}
+
+func (f *fanInReader) accept() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		f.Lock()
+		if f.closed {
+			f.Unlock()
+			conn.Close()
+			return
+		}
+		f.conns[conn] = struct{}{}
+		f.Unlock()
+
+		f.wg.Add(1)
+		go f.merge(conn)
+	}
+}
+
+func (f *fanInReader) merge(conn net.Conn) {
+	defer f.wg.Done()
+	defer func() {
+		f.Lock()
+		delete(f.conns, conn)
+		f.Unlock()
+		conn.Close()
+	}()
+
+	// line is written atomically to the pipe
+	br := bufio.NewReader(conn)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			f.wmu.Lock()
+			_, werr := f.pw.Write(line)
+			f.wmu.Unlock()
+			if werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (f *fanInReader) Read(p []byte) (int, error) {
+	return f.pr.Read(p)
+}
+
+func (f *fanInReader) Close() error {
+	err := f.ln.Close()
+
+	// unblock pending writes before closing connections
+	f.pr.Close()
+	f.Lock()
+	f.closed = true
+	for conn := range f.conns {
+		conn.Close()
+	}
+	f.Unlock()
+	f.wg.Wait()
+
+	return err
+}
@@ -0,0 +1,281 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultReconnectBuffer = 64 << 10
+	defaultCloseTimeout    = 5 * time.Second
+)
+
+// ErrDataDropped returned by ReconnectWriter.Close when some data was not delivered.
+var ErrDataDropped = errors.New("data dropped")
+
+// ReconnectOptions configure ReconnectWriter.
+type ReconnectOptions struct {
+	// MinBackoff and MaxBackoff bound the delay between redial attempts
+	// (default 100ms and 30s).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// BufferSize is the maximum number of bytes kept while disconnected.
+	// Data exceeding the buffer is dropped. Negative disables buffering.
+	BufferSize int
+	// CloseTimeout bound the time Close spends to dial and flush
+	// buffered data (default 5s). Negative disables the last attempt.
+	CloseTimeout time.Duration
+}
+
+// ReconnectWriter is network writer which redial with backoff when the
+// connection is lost. While disconnected, writes do not fail: data is kept in
+// a bounded buffer (sent once reconnected), and the overflow is dropped.
+// Note that data written just before the peer goes away may be lost
+// without being reported, as the failure is only detected by later writes.
+type ReconnectWriter struct {
+	mu       sync.Mutex
+	dial     func(ctx context.Context) (net.Conn, error)
+	opts     ReconnectOptions
+	conn     net.Conn
+	buf      []byte
+	closed   bool
+	dropped  atomic.Int64
+	connects atomic.Int64
+	ctx      context.Context
+	cancel   context.CancelFunc
+	chLost   chan struct{}
+	done     chan struct{}
+}
+
+// NewReconnectWriter create writer using given dial function.
+// The first connection is dialed in background, so the function
+// does not fail when the peer is not available yet.
+func NewReconnectWriter(ctx context.Context, dial func(ctx context.Context) (net.Conn, error), opts ReconnectOptions) *ReconnectWriter {
+	if opts.BufferSize == 0 {
+		opts.BufferSize = defaultReconnectBuffer
+	}
+	if opts.CloseTimeout == 0 {
+		opts.CloseTimeout = defaultCloseTimeout
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	w := &ReconnectWriter{
+		dial:   dial,
+		opts:   opts,
+		ctx:    cctx,
+		cancel: cancel,
+		chLost: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	w.chLost <- struct{}{}
+	go w.redial()
+
+	return w
+}
+
+// reconnectFromDescriptor wrap network descriptor with `reconnect=1` query.
+// Options: backoff=100ms, max_backoff=30s, buffer=64KB.
+func reconnectFromDescriptor(ctx context.Context, u *url.URL, dial func(ctx context.Context) (net.Conn, error)) (io.ReadWriteCloser, error) {
+	q := u.Query()
+	opts := ReconnectOptions{}
+	if v := q.Get("backoff"); v != "" {
+		d, ok := ToDuration(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid backoff: %s", v)
+		}
+		opts.MinBackoff = d
+	}
+	if v := q.Get("max_backoff"); v != "" {
+		d, ok := ToDuration(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid max_backoff: %s", v)
+		}
+		opts.MaxBackoff = d
+	}
+	if v := q.Get("buffer"); v != "" {
+		n, ok := ToByteSize(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid buffer: %s", v)
+		}
+		opts.BufferSize = int(n)
+		if n == 0 {
+			opts.BufferSize = -1
+		}
+	}
+
+	return writeOnlyRWCloser{NewReconnectWriter(ctx, dial, opts)}, nil
+}
+
+// Dropped return number of bytes dropped because the buffer was full.
+func (w *ReconnectWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Connects return number of successful connections, including the first one.
+func (w *ReconnectWriter) Connects() int64 {
+	return w.connects.Load()
+}
+
+// Connected return true if the writer currently has connection.
+func (w *ReconnectWriter) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.conn != nil
+}
+
+func (w *ReconnectWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.conn != nil {
+		n, err := w.conn.Write(p)
+		if err == nil {
+			return n, nil
+		}
+		w.lost()
+		w.buffer(p[n:])
+		return len(p), nil
+	}
+	w.buffer(p)
+	return len(p), nil
+}
+
+// buffer keep data while disconnected, must be called with lock held.
+func (w *ReconnectWriter) buffer(p []byte) {
+	free := max(w.opts.BufferSize-len(w.buf), 0)
+	n := min(free, len(p))
+	w.buf = append(w.buf, p[:n]...)
+	if drop := len(p) - n; drop > 0 {
+		w.dropped.Add(int64(drop))
+	}
+}
+
+// lost close current connection and trigger redial, must be called with lock held.
+func (w *ReconnectWriter) lost() {
+	w.conn.Close()
+	w.conn = nil
+	select {
+	case w.chLost <- struct{}{}:
+	default:
+	}
+}
+
+func (w *ReconnectWriter) redial() {
+	defer close(w.done)
+
+	policy := SupervisePolicy{
+		MinBackoff: w.opts.MinBackoff,
+		MaxBackoff: w.opts.MaxBackoff,
+		Jitter:     0.2,
+	}
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.chLost:
+		}
+
+		for attempt := 1; ; attempt++ {
+			conn, err := w.dial(w.ctx)
+			if err == nil && w.connected(conn) {
+				break
+			}
+			tm := time.NewTimer(policy.backoff(attempt))
+			select {
+			case <-w.ctx.Done():
+				tm.Stop()
+				return
+			case <-tm.C:
+			}
+		}
+	}
+}
+
+// connected flush buffered data into new connection and use it for writing.
+func (w *ReconnectWriter) connected(conn net.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		n, err := conn.Write(w.buf)
+		w.buf = w.buf[n:]
+		if err != nil {
+			conn.Close()
+			return false
+		}
+		w.buf = nil
+	}
+	w.conn = conn
+	w.connects.Add(1)
+	return true
+}
+
+// Close stop redialing, flush buffered data (see CloseContext)
+// and close the connection. It waits at most opts.CloseTimeout.
+func (w *ReconnectWriter) Close() error {
+	if w.opts.CloseTimeout < 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return w.CloseContext(ctx)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.CloseTimeout)
+	defer cancel()
+
+	return w.CloseContext(ctx)
+}
+
+// CloseContext stop redialing and close the connection. When disconnected,
+// it dials once more (until ctx is done) to flush buffered data.
+// Data which is not delivered is counted as dropped, and ErrDataDropped
+// is returned if any data was dropped during the writer lifetime.
+func (w *ReconnectWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cancel()
+	w.mu.Unlock()
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	if w.conn == nil && len(w.buf) > 0 && ctx.Err() == nil {
+		conn, derr := w.dial(ctx)
+		if derr == nil {
+			w.conn = conn
+		}
+	}
+	if w.conn != nil {
+		if len(w.buf) > 0 {
+			if dl, ok := ctx.Deadline(); ok {
+				w.conn.SetWriteDeadline(dl)
+			}
+			n, _ := w.conn.Write(w.buf)
+			w.buf = w.buf[n:]
+		}
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	w.dropped.Add(int64(len(w.buf)))
+	w.buf = nil
+
+	if n := w.dropped.Load(); n > 0 {
+		err = errors.Join(err, fmt.Errorf("%w: %d bytes", ErrDataDropped, n))
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package pola_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+// lineServer accept connections and forward received lines into channel.
+// Returned function stop the listener and close all accepted connections.
+func lineServer(t *testing.T, addr string, lines chan<- string) (net.Listener, func()) {
+	ln, err := net.Listen("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	mu := sync.Mutex{}
+	conns := []net.Conn{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+			go func() {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				for {
+					line, err := br.ReadString('\n')
+					if err != nil {
+						return
+					}
+					lines <- line
+				}
+			}()
+		}
+	}()
+	return ln, func() {
+		ln.Close()
+		mu.Lock()
+		for _, c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}
+}
+
+func TestReconnectDescriptor(t *testing.T) {
+	lines := make(chan string, 100)
+	ln, stop := lineServer(t, "127.0.0.1:0", lines)
+	addr := ln.Addr().String()
+
+	w, err := pola.WriteCloserFromDescriptor("tcp://" + addr + "?reconnect=1&backoff=5ms&max_backoff=20ms&buffer=1KB")
+	assert.NoError(t, err)
+	rw := w.(*pola.ReconnectWriter)
+	for !rw.Connected() {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = w.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first\n", <-lines)
+
+	// stop the collector, writes keep succeeding
+	stop()
+	time.Sleep(10 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for rw.Connected() {
+		if time.Now().After(deadline) {
+			t.Fatal("connection loss is not detected")
+		}
+		// the loss is detected by subsequent writes
+		_, err = w.Write([]byte("probe\n"))
+		assert.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	for len(lines) > 0 {
+		<-lines
+	}
+	_, err = w.Write([]byte("buffered\n"))
+	assert.NoError(t, err)
+
+	// restart the collector on the same address
+	_, stop = lineServer(t, addr, lines)
+	defer stop()
+	received := ""
+	for received != "buffered\n" {
+		select {
+		case received = <-lines:
+		case <-time.After(5 * time.Second):
+			t.Fatal("buffered data is not received")
+		}
+	}
+	_, err = w.Write([]byte("after\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", <-lines)
+	assert.GreaterOrEqual(t, rw.Connects(), int64(2))
+	assert.NoError(t, w.Close())
+}
+
+func TestReconnectWriterDropped(t *testing.T) {
+	w := pola.NewReconnectWriter(t.Context(), func(ctx context.Context) (net.Conn, error) {
+		return nil, errors.New("unavailable")
+	}, pola.ReconnectOptions{BufferSize: 4, MinBackoff: time.Millisecond})
+
+	n, err := w.Write([]byte("0123456789"))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, int64(6), w.Dropped())
+	assert.ErrorIs(t, w.Close(), pola.ErrDataDropped)
+	assert.Equal(t, int64(10), w.Dropped())
+	_, err = w.Write([]byte("x"))
+	assert.Error(t, err)
+}
+
+func TestReconnectWriterCloseFlush(t *testing.T) {
+	lines := make(chan string, 10)
+	ln, stop := lineServer(t, "127.0.0.1:0", lines)
+	defer stop()
+
+	// write before the first connection is established
+	w, err := pola.WriteCloserFromDescriptor("tcp://" + ln.Addr().String() + "?reconnect=1")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("important\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, int64(0), w.(*pola.ReconnectWriter).Dropped())
+	select {
+	case line := <-lines:
+		assert.Equal(t, "important\n", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("buffered data is not flushed on Close")
+	}
+}
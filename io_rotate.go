@@ -0,0 +1,243 @@
+package pola
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RotateOptions configure RotatingWriter.
+type RotateOptions struct {
+	// MaxSize rotate the file once it reaches given size (bytes). Zero disables size rotation.
+	MaxSize int64
+	// Interval rotate the file once it has been open for given duration. Zero disables time rotation.
+	Interval time.Duration
+	// MaxFiles is number of backups kept. Zero or negative keeps all backups.
+	MaxFiles int
+	// Compress gzip rotated files.
+	Compress bool
+	// Mode of created files (default 0666).
+	Mode os.FileMode
+}
+
+// RotatingWriter write into file which is rotated by size and/or time.
+// Backups are named <name>.1 (most recent), <name>.2, ... with optional .gz suffix.
+// Rotated file is compressed in background, without blocking writers.
+// Errors of automatic rotation are returned by Close.
+// RotatingWriter is safe for concurrent usage.
+type RotatingWriter struct {
+	mu     sync.Mutex
+	name   string
+	opts   RotateOptions
+	f      *os.File
+	size   int64
+	opened time.Time
+	err    error
+
+	// background compression
+	wg    sync.WaitGroup
+	gzErr error
+}
+
+// NewRotatingWriter open (append) file `name` for writing with rotation.
+func NewRotatingWriter(name string, opts RotateOptions) (*RotatingWriter, error) {
+	if opts.Mode == 0 {
+		opts.Mode = 0666
+	}
+	w := &RotatingWriter{name: name, opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// rotateFromDescriptor create rotating writer, e.g.
+// rotate:///var/log/app.log?max_size=100MB&max_files=7&compress=1&interval=24h&mode=0640
+func rotateFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	if !wr {
+		return nil, fmt.Errorf("rotate %s: %w", u.Path, ErrNotReadable)
+	}
+	q := u.Query()
+	opts := RotateOptions{Compress: ToBool(q.Get("compress"))}
+	if v := q.Get("max_size"); v != "" {
+		n, ok := ToByteSize(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid max_size: %s", v)
+		}
+		opts.MaxSize = n
+	}
+	if v := q.Get("interval"); v != "" {
+		d, ok := ToDuration(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid interval: %s", v)
+		}
+		opts.Interval = d
+	}
+	if v := q.Get("max_files"); v != "" {
+		n, ok := ToInt(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid max_files: %s", v)
+		}
+		opts.MaxFiles = int(n)
+	}
+	if v := q.Get("mode"); v != "" {
+		m, err := strconv.ParseUint(v, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file mode: %s", v)
+		}
+		opts.Mode = os.FileMode(m)
+	}
+	w, err := NewRotatingWriter(u.Path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return writeOnlyRWCloser{w}, nil
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, w.opts.Mode)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f = f
+	w.size = fi.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	due := w.opts.Interval > 0 && time.Since(w.opened) >= w.opts.Interval
+	full := w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize
+	if due || full {
+		if err := w.rotate(); err != nil {
+			// keep writing into current file if it is reopened
+			w.err = errors.Join(w.err, err)
+			if w.f == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate force rotation of current file.
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+func (w *RotatingWriter) backupName(i int) string {
+	name := w.name + "." + strconv.Itoa(i)
+	if w.opts.Compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// rotate move current file to backup and reopen it.
+// The file is always reopened, even if moving fails.
+func (w *RotatingWriter) rotate() error {
+	err := w.f.Close()
+	w.f = nil
+
+	// previous file must be compressed before backups are shifted
+	w.wg.Wait()
+	err = errors.Join(err, w.gzErr)
+	w.gzErr = nil
+	if err == nil {
+		err = w.shift()
+	}
+	return errors.Join(err, w.open())
+}
+
+func (w *RotatingWriter) shift() error {
+	// find the last backup to shift
+	last := 1
+	for PathExists(w.backupName(last)) && (w.opts.MaxFiles <= 0 || last < w.opts.MaxFiles) {
+		last++
+	}
+	for i := last; i > 1; i-- {
+		if err := os.Rename(w.backupName(i-1), w.backupName(i)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if !w.opts.Compress {
+		return os.Rename(w.name, w.backupName(1))
+	}
+
+	// compress outside the lock
+	pending := w.name + ".rotated"
+	if err := os.Rename(w.name, pending); err != nil {
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.gzErr = gzipFile(pending, w.backupName(1), w.opts.Mode)
+	}()
+	return nil
+}
+
+// gzipFile compress src into dst and remove src.
+func gzipFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = copyBuffer(zw, in)
+	err = errors.Join(err, zw.Close(), out.Close())
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// Close close current file, wait for pending compression and
+// return errors of automatic rotation (if any).
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.wg.Wait()
+	err := errors.Join(w.err, w.gzErr)
+	w.err, w.gzErr = nil, nil
+	if w.f == nil {
+		return err
+	}
+	err = errors.Join(err, w.f.Close())
+	w.f = nil
+	return err
+}
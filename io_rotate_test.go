@@ -0,0 +1,99 @@
+package pola_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+
+	w, err := pola.WriteCloserFromDescriptor("rotate://" + pth + "?max_size=1KB&max_files=2&compress=1")
+	assert.NoError(t, err)
+	wg := sync.WaitGroup{}
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				fmt.Fprintf(w, "%099d\n", i)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, w.Close())
+
+	entries, _ := os.ReadDir(dir)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"app.log", "app.log.1.gz", "app.log.2.gz"}, names)
+
+	f, _ := os.Open(pth + ".1.gz")
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(zr)
+	assert.Len(t, data, 1000)
+
+	_, err = pola.WriteCloserFromDescriptor("rotate://" + pth + "?max_size=abc")
+	assert.Error(t, err)
+	_, err = pola.ReadCloserFromDescriptor("rotate://" + pth)
+	assert.ErrorIs(t, err, pola.ErrNotReadable)
+}
+
+func TestRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+	w, err := pola.NewRotatingWriter(pth, pola.RotateOptions{})
+	assert.NoError(t, err)
+	for i := range 3 {
+		fmt.Fprintf(w, "file %d\n", i)
+		assert.NoError(t, w.Rotate())
+	}
+	assert.NoError(t, w.Close())
+
+	for i, exp := range []string{"", "file 2\n", "file 1\n", "file 0\n"} {
+		name := pth
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", pth, i)
+		}
+		data, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, string(data))
+	}
+	_, err = w.Write([]byte("closed"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingWriterFailure(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+
+	// backup can not be created, rotation fails
+	assert.NoError(t, os.Mkdir(pth+".1", 0755))
+	w, err := pola.NewRotatingWriter(pth, pola.RotateOptions{MaxSize: 2, MaxFiles: 1})
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, "aa")
+	assert.NoError(t, err)
+	assert.Error(t, w.Rotate())
+
+	// logging continues into the same file
+	_, err = io.WriteString(w, "bb")
+	assert.NoError(t, err)
+	assert.Error(t, w.Close())
+
+	data, err := os.ReadFile(pth)
+	assert.NoError(t, err)
+	assert.Equal(t, "aabb", string(data))
+}
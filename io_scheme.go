@@ -0,0 +1,187 @@
+package pola
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DescriptorOpener open descriptor for reading or writing (wr == true).
+// Returned value only needs to support the requested direction,
+// the other direction may return ErrNotReadable/ErrNotWritable.
+type DescriptorOpener func(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error)
+
+var descriptorSchemes = newCowRegistry[string, DescriptorOpener]()
+
+// specialDescriptors are descriptor names which are checked before URL parsing.
+// The opener receives URL with the name as its Path.
+var specialDescriptors = map[string]DescriptorOpener{
+	IoEmpty:   openNullDescriptor,
+	IoNull:    openNullDescriptor,
+	IoDevNull: openNullDescriptor,
+	IoStdin:   openStdDescriptor,
+	IoStdout:  openStdDescriptor,
+	IoStderr:  openStdDescriptor,
+}
+
+// RegisterDescriptorScheme register opener for descriptors with given URL scheme,
+// e.g. "s3" for "s3://bucket/key". Scheme is case insensitive.
+// Descriptor without scheme is always treated as filename.
+// The query is passed to the opener as is (generic options such as
+// `compress` and `rate` are only applied to built-in schemes).
+func RegisterDescriptorScheme(scheme string, opener DescriptorOpener) error {
+	return descriptorSchemes.Register(strings.ToLower(scheme), opener)
+}
+
+// UnregisterDescriptorScheme remove opener of given scheme.
+// It returns false if the scheme is not registered.
+func UnregisterDescriptorScheme(scheme string) bool {
+	return descriptorSchemes.remove(strings.ToLower(scheme))
+}
+
+// DescriptorSchemes return list of registered schemes.
+func DescriptorSchemes() []string {
+	m := descriptorSchemes.Map()
+	schemes := make([]string, 0, len(m))
+	for k := range m {
+		schemes = append(schemes, k)
+	}
+	return schemes
+}
+
+func init() {
+	builtins := map[string]DescriptorOpener{
+		"tcp":         dialFromDescriptor,
+		"tcp4":        dialFromDescriptor,
+		"tcp6":        dialFromDescriptor,
+		"udp":         dialFromDescriptor,
+		"udp4":        dialFromDescriptor,
+		"udp6":        dialFromDescriptor,
+		"unix":        dialFromDescriptor,
+		"unixgram":    dialFromDescriptor,
+		"tls":         tlsFromDescriptor,
+		"tcp+tls":     tlsFromDescriptor,
+		"tcp-listen":  listenFromDescriptor,
+		"tcp4-listen": listenFromDescriptor,
+		"tcp6-listen": listenFromDescriptor,
+		"unix-listen": listenFromDescriptor,
+		"http":        httpFromDescriptor,
+		"https":       httpFromDescriptor,
+		"file":        openFileDescriptor,
+		"mem":         openMemDescriptor,
+		"fd":          openFdDescriptor,
+		"exec":        execFromDescriptor,
+		"tail":        tailFromDescriptor,
+		"rotate":      rotateFromDescriptor,
+		"tee":         teeFromDescriptor,
+		"tee+drop":    teeFromDescriptor,
+		"tee+best":    teeFromDescriptor,
+	}
+	for scheme, opener := range builtins {
+		descriptorSchemes.MustRegister(scheme, opener)
+	}
+}
+
+func openNullDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	return devNull{}, nil
+}
+
+// openStdDescriptor open <stdin>, <stdout> or <stderr>
+func openStdDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	switch strings.ToLower(u.Path) {
+	case IoStdin:
+		if !wr {
+			return readOnlyRWCloser{io.NopCloser(os.Stdin)}, nil
+		}
+	case IoStdout:
+		if wr {
+			return writeOnlyRWCloser{NopWriteCloser(os.Stdout)}, nil
+		}
+	case IoStderr:
+		if wr {
+			return writeOnlyRWCloser{NopWriteCloser(os.Stderr)}, nil
+		}
+	}
+	if wr {
+		return nil, fmt.Errorf("%s: %w", u.Path, ErrNotWritable)
+	}
+	return nil, fmt.Errorf("%s: %w", u.Path, ErrNotReadable)
+}
+
+// openFdDescriptor open file descriptor number, e.g. fd://3.
+// The descriptor is owned by returned file and closed on Close,
+// except the standard descriptors (0, 1 and 2) which are never closed.
+func openFdDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	fd, err := strconv.ParseUint(u.Host, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptor: %s", u.Host)
+	}
+	switch fd {
+	case 0:
+		return NopReadWriteCloser(os.Stdin), nil
+	case 1:
+		return NopReadWriteCloser(os.Stdout), nil
+	case 2:
+		return NopReadWriteCloser(os.Stderr), nil
+	}
+	return os.NewFile(uintptr(fd), "fd"+u.Host), nil
+}
+
+// memFiles hold content of mem:// descriptors
+var memFiles = NewSyncRegistry[string, *memFile]()
+
+type memFile struct {
+	sync.Mutex
+	data []byte
+}
+
+// MemDescriptorData return copy of the content written into mem://name descriptor.
+func MemDescriptorData(name string) ([]byte, bool) {
+	mf, err := memFiles.Get(name)
+	if err != nil {
+		return nil, false
+	}
+	mf.Lock()
+	defer mf.Unlock()
+
+	return bytes.Clone(mf.data), true
+}
+
+// openMemDescriptor open in-memory file, e.g. mem://name.
+// Writing replace the content (or append with `append=1`),
+// and reading return snapshot of the content at the time of opening.
+func openMemDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	name := u.Host + u.Path
+	if !wr {
+		data, ok := MemDescriptorData(name)
+		if !ok {
+			return nil, fmt.Errorf("mem://%s: %w", name, os.ErrNotExist)
+		}
+		return readOnlyRWCloser{io.NopCloser(bytes.NewReader(data))}, nil
+	}
+
+	mf := &memFile{}
+	if err := memFiles.Register(name, mf); err != nil {
+		mf = memFiles.MustGet(name)
+	}
+	if !ToBool(u.Query().Get("append")) {
+		mf.Lock()
+		mf.data = nil
+		mf.Unlock()
+	}
+	return writeOnlyRWCloser{NopWriteCloser(mf)}, nil
+}
+
+func (m *memFile) Write(p []byte) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.data = append(m.data, p...)
+	return len(p), nil
+}
@@ -0,0 +1,78 @@
+package pola_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemDescriptor(t *testing.T) {
+	assert.NoError(t, writeDescriptor(t, "mem://config", "debug: true\n"))
+	assert.NoError(t, writeDescriptor(t, "mem://config?append=1", "name: pola\n"))
+
+	data, ok := pola.MemDescriptorData("config")
+	assert.True(t, ok)
+	assert.Equal(t, "debug: true\nname: pola\n", string(data))
+
+	r, err := pola.ReadCloserFromDescriptor("mem://config")
+	assert.NoError(t, err)
+	var dst map[string]any
+	assert.NoError(t, pola.NewDecoder(r, pola.ExtYaml).Decode(&dst))
+	assert.NoError(t, r.Close())
+	assert.Equal(t, "pola", dst["name"])
+
+	// compressed round-trip
+	assert.NoError(t, writeDescriptor(t, "mem://data.gz", "compressed"))
+	r, err = pola.ReadCloserFromDescriptor("mem://data.gz")
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	assert.Equal(t, "compressed", string(data))
+
+	_, err = pola.ReadCloserFromDescriptor("mem://missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDescriptorScheme(t *testing.T) {
+	opener := func(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+		return pola.NopReadWriteCloser(&bytes.Buffer{}), nil
+	}
+	assert.NoError(t, pola.RegisterDescriptorScheme("Test", opener))
+	defer pola.UnregisterDescriptorScheme("test")
+	assert.ErrorIs(t, pola.RegisterDescriptorScheme("test", opener), pola.ErrDuplicateEntry)
+	assert.Contains(t, pola.DescriptorSchemes(), "test")
+	assert.NoError(t, writeDescriptor(t, "TEST://anything", "x"))
+
+	_, err := pola.WriteCloserFromDescriptor(pola.IoStdin)
+	assert.ErrorIs(t, err, pola.ErrNotWritable)
+	_, err = pola.ReadCloserFromDescriptor(pola.IoStdout)
+	assert.ErrorIs(t, err, pola.ErrNotReadable)
+
+	w, err := pola.WriteCloserFromDescriptor(pola.IoStdout)
+	assert.NoError(t, err)
+	_, ok := w.(io.ReaderFrom)
+	assert.True(t, ok)
+
+}
+
+func TestDescriptorFilenameScheme(t *testing.T) {
+	// relative filename matching a scheme name is still a file
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"exec", "tail", "mem"} {
+		assert.NoError(t, writeDescriptor(t, name, "file "+name))
+		r, err := pola.ReadCloserFromDescriptor(name)
+		if assert.NoError(t, err) {
+			data, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, "file "+name, string(data))
+			assert.NoError(t, r.Close())
+		}
+	}
+
+	assert.False(t, pola.UnregisterDescriptorScheme("not-registered"))
+}
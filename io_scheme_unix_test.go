@@ -0,0 +1,41 @@
+//go:build unix
+
+package pola_test
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFdDescriptor(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer pr.Close()
+
+	// descriptor is owned (and closed) by the writer
+	fd, err := syscall.Dup(int(pw.Fd()))
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Close())
+
+	w, err := pola.WriteCloserFromDescriptor("fd://" + strconv.Itoa(fd))
+	assert.NoError(t, err)
+	io.WriteString(w, "via fd")
+	assert.NoError(t, w.Close())
+	data, _ := io.ReadAll(pr)
+	assert.Equal(t, "via fd", string(data))
+
+	// standard descriptors are not closed
+	w, err = pola.WriteCloserFromDescriptor("fd://1")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	_, err = os.Stdout.Stat()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,183 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultTailPoll = 250 * time.Millisecond
+
+// tailFromDescriptor follow growing file, e.g. tail:///var/log/app.log
+// or file:///var/log/app.log?follow=1. Supported query options are:
+//   - poll=250ms: interval for checking new data
+//   - from=end: start from the end of the file instead of the beginning
+func tailFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	if wr {
+		return nil, fmt.Errorf("tail %s: %w", u.Path, ErrNotWritable)
+	}
+	q := u.Query()
+	poll := defaultTailPoll
+	if p := q.Get("poll"); p != "" {
+		d, ok := ToDuration(p)
+		if !ok || d <= 0 {
+			return nil, fmt.Errorf("invalid poll interval: %s", p)
+		}
+		poll = d
+	}
+	t, err := NewTailReader(ctx, u.Path, poll, strings.EqualFold(q.Get("from"), "end"))
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyRWCloser{t}, nil
+}
+
+// TailReader behaves like `tail -F`. It keeps reading as the file grows,
+// reopen the file after it is rotated (replaced) or truncated,
+// and return io.EOF once closed or its context is canceled.
+type TailReader struct {
+	mu   sync.Mutex
+	ctx  context.Context
+	name string
+	poll time.Duration
+	f    *os.File
+	off  int64
+	done chan struct{}
+	once sync.Once
+}
+
+// NewTailReader create reader following file `name`, checking for new data every `poll`.
+// When `fromEnd` is true, existing content is skipped.
+// The file does not need to exist yet.
+func NewTailReader(ctx context.Context, name string, poll time.Duration, fromEnd bool) (*TailReader, error) {
+	t := &TailReader{
+		ctx:  ctx,
+		name: name,
+		poll: poll,
+		done: make(chan struct{}),
+	}
+	if err := t.open(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if fromEnd && t.f != nil {
+		off, err := t.f.Seek(0, io.SeekEnd)
+		if err != nil {
+			t.f.Close()
+			return nil, err
+		}
+		t.off = off
+	}
+	return t, nil
+}
+
+func (t *TailReader) open() error {
+	f, err := os.Open(t.name)
+	if err != nil {
+		return err
+	}
+	t.f = f
+	t.off = 0
+	return nil
+}
+
+// reopen check whether the file was rotated or truncated.
+func (t *TailReader) reopen() error {
+	fi, err := os.Stat(t.name)
+	if err != nil {
+		// rotated, new file is not created yet
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if t.f == nil {
+		return t.open()
+	}
+	cur, err := t.f.Stat()
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(fi, cur) {
+		t.f.Close()
+		t.f = nil
+		return t.open()
+	}
+	if fi.Size() < t.off {
+		// truncated
+		if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.off = 0
+	}
+	return nil
+}
+
+func (t *TailReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		n, err := t.read(p)
+		if n > 0 || (err != nil && err != io.EOF) {
+			return n, err
+		}
+
+		// wait for more data
+		tm := time.NewTimer(t.poll)
+		select {
+		case <-t.done:
+			tm.Stop()
+			return 0, io.EOF
+		case <-t.ctx.Done():
+			tm.Stop()
+			return 0, io.EOF
+		case <-tm.C:
+		}
+	}
+}
+
+func (t *TailReader) read(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return 0, io.EOF
+	default:
+	}
+	if t.f != nil {
+		n, err := t.f.Read(p)
+		t.off += int64(n)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+	}
+	// end of current file, check rotation
+	if err := t.reopen(); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+// Close stop following the file, pending Read return io.EOF.
+func (t *TailReader) Close() error {
+	t.once.Do(func() {
+		close(t.done)
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.f != nil {
+		err := t.f.Close()
+		t.f = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package pola_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(pth, []byte("line 1\n"), 0644))
+
+	r, err := pola.ReadCloserFromDescriptor("tail://" + pth + "?poll=5ms")
+	assert.NoError(t, err)
+	br := bufio.NewReader(r)
+	readLine := func() string {
+		line, err := br.ReadString('\n')
+		assert.NoError(t, err)
+		return line
+	}
+	assert.Equal(t, "line 1\n", readLine())
+
+	// grow
+	f, _ := os.OpenFile(pth, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("line 2\n")
+	f.Close()
+	assert.Equal(t, "line 2\n", readLine())
+
+	// rotate
+	assert.NoError(t, os.Rename(pth, pth+".1"))
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(pth, []byte("line 3\n"), 0644))
+	assert.Equal(t, "line 3\n", readLine())
+
+	// truncate
+	assert.NoError(t, os.WriteFile(pth, []byte("4\n"), 0644))
+	assert.Equal(t, "4\n", readLine())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		r.Close()
+	}()
+	_, err = br.ReadString('\n')
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestTailReaderContext(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(pth, []byte("skipped\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tr, err := pola.NewTailReader(ctx, pth, 5*time.Millisecond, true)
+	assert.NoError(t, err)
+	defer tr.Close()
+
+	go func() {
+		f, _ := os.OpenFile(pth, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString("new\n")
+		f.Close()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	data, err := io.ReadAll(tr)
+	assert.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+
+	r, err := pola.ReadCloserFromDescriptor("file://" + pth + "?follow=1&poll=5ms")
+	assert.NoError(t, err)
+	buf := make([]byte, 12)
+	n, err := io.ReadFull(r, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "skipped\nnew\n", string(buf[:n]))
+	assert.NoError(t, r.Close())
+}
@@ -0,0 +1,153 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNoTeeTarget = errors.New("no tee target")
+)
+
+// TeePolicy determines how TeeWriteCloser handle a failing target.
+type TeePolicy int
+
+const (
+	// TeeFailAll return error as soon as one of the targets fails.
+	TeeFailAll TeePolicy = iota
+	// TeeDropFailed close and remove failing target, and continue with the rest.
+	// Write and close errors of dropped targets are returned by Close.
+	TeeDropFailed
+	// TeeBestEffort ignore write errors and keep all targets.
+	TeeBestEffort
+)
+
+var teeSchemes = map[string]TeePolicy{
+	"tee":      TeeFailAll,
+	"tee+drop": TeeDropFailed,
+	"tee+best": TeeBestEffort,
+}
+
+type teeWriteCloser struct {
+	sync.Mutex
+	policy  TeePolicy
+	targets []teeTarget
+	closers Closers
+	// errors of dropped targets
+	dropped error
+}
+
+// teeTarget is a target with its closer, which close only once
+type teeTarget struct {
+	io.Writer
+	idx    int
+	closer io.Closer
+}
+
+// NewTeeWriteCloser create writer which duplicate writes to every target.
+// Close closes remaining targets and join their errors
+// (with errors of dropped targets, if any).
+func NewTeeWriteCloser(policy TeePolicy, targets ...io.WriteCloser) io.WriteCloser {
+	t := &teeWriteCloser{
+		policy:  policy,
+		closers: NewClosers(),
+	}
+	for i, w := range targets {
+		c := SafeCloser(w)
+		t.targets = append(t.targets, teeTarget{Writer: w, idx: i, closer: c})
+		t.closers.Append(c)
+	}
+	return t
+}
+
+// teeFromDescriptor open comma separated targets, e.g.
+// tee:<stdout>,file:///tmp/out.log,tcp://collector:5000.
+// Scheme selects the policy: `tee` (TeeFailAll), `tee+drop` (TeeDropFailed)
+// or `tee+best` (TeeBestEffort). Targets can not contain comma.
+func teeFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	if !wr {
+		return nil, fmt.Errorf("%s: %w", u.Scheme, ErrNotReadable)
+	}
+	list := u.Opaque
+	if u.RawQuery != "" {
+		list += "?" + u.RawQuery
+	}
+
+	cs := NewClosers()
+	var targets []io.WriteCloser
+	for desc := range strings.SplitSeq(list, ",") {
+		desc = strings.TrimSpace(desc)
+		if desc == "" {
+			continue
+		}
+		w, err := writeCloserFromDescriptor(ctx, desc)
+		if err != nil {
+			return nil, errors.Join(err, cs.Close())
+		}
+		cs.Append(w)
+		targets = append(targets, w)
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoTeeTarget
+	}
+
+	policy := teeSchemes[strings.ToLower(u.Scheme)]
+	return writeOnlyRWCloser{NewTeeWriteCloser(policy, targets...)}, nil
+}
+
+func (t *teeWriteCloser) Write(p []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.targets) == 0 {
+		return 0, ErrNoTeeTarget
+	}
+
+	var errs error
+	written := 0
+	alive := t.targets[:0]
+	for _, w := range t.targets {
+		n, err := w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err == nil {
+			written++
+			alive = append(alive, w)
+			continue
+		}
+		err = fmt.Errorf("tee target %d: %w", w.idx, err)
+		switch t.policy {
+		case TeeFailAll:
+			return n, err
+		case TeeDropFailed:
+			err = errors.Join(err, w.closer.Close())
+			t.dropped = errors.Join(t.dropped, err)
+		case TeeBestEffort:
+			alive = append(alive, w)
+		}
+		errs = errors.Join(errs, err)
+	}
+	t.targets = alive
+
+	// fail only when none of the targets succeeded
+	if written == 0 {
+		return 0, errs
+	}
+	return len(p), nil
+}
+
+func (t *teeWriteCloser) Close() error {
+	t.Lock()
+	defer t.Unlock()
+
+	t.targets = nil
+	err := errors.Join(t.dropped, t.closers.Close())
+	t.dropped = nil
+	return err
+}
@@ -0,0 +1,90 @@
+package pola_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+type failWriter struct {
+	err    error
+	closed bool
+}
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	return 0, f.err
+}
+func (f *failWriter) Close() error {
+	f.closed = true
+	return f.err
+}
+
+func TestTeeDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "out.log.gz")
+	assert.NoError(t, writeDescriptor(t, "tee:mem://tee-a,file://"+pth+"?mkdir=1, <null>", "fan-out"))
+
+	data, _ := pola.MemDescriptorData("tee-a")
+	assert.Equal(t, "fan-out", string(data))
+	r, err := pola.ReadCloserFromDescriptor(pth)
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "fan-out", string(data))
+
+	_, err = pola.WriteCloserFromDescriptor("tee:")
+	assert.ErrorIs(t, err, pola.ErrNoTeeTarget)
+	_, err = pola.WriteCloserFromDescriptor("tee:mem://tee-b," + filepath.Join(dir, "missing", "x"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = pola.ReadCloserFromDescriptor("tee:mem://tee-b")
+	assert.ErrorIs(t, err, pola.ErrNotReadable)
+}
+
+func TestTeePolicy(t *testing.T) {
+	errWrite := errors.New("write failed")
+
+	// fail all
+	fw := &failWriter{err: errWrite}
+	mem, _ := pola.WriteCloserFromDescriptor("mem://tee-policy")
+	w := pola.NewTeeWriteCloser(pola.TeeFailAll, fw, mem)
+	_, err := w.Write([]byte("x"))
+	assert.ErrorIs(t, err, errWrite)
+	err = w.Close()
+	assert.ErrorIs(t, err, errWrite)
+	assert.True(t, fw.closed)
+
+	// drop failed target
+	fw = &failWriter{err: errWrite}
+	mem, _ = pola.WriteCloserFromDescriptor("mem://tee-policy")
+	w = pola.NewTeeWriteCloser(pola.TeeDropFailed, fw, mem)
+	for range 2 {
+		n, err := w.Write([]byte("ab"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		// closed as soon as it is dropped
+		assert.True(t, fw.closed)
+	}
+	data, _ := pola.MemDescriptorData("tee-policy")
+	assert.Equal(t, "abab", string(data))
+	err = w.Close()
+	assert.ErrorIs(t, err, errWrite)
+	assert.ErrorContains(t, err, "tee target 0")
+
+	// best effort, fail only if all targets fail
+	w = pola.NewTeeWriteCloser(pola.TeeBestEffort, &failWriter{err: errWrite}, &failWriter{err: errWrite})
+	_, err = w.Write([]byte("x"))
+	assert.ErrorIs(t, err, errWrite)
+	_, err = w.Write([]byte("x"))
+	assert.ErrorIs(t, err, errWrite)
+
+	w = pola.NewTeeWriteCloser(pola.TeeDropFailed, &failWriter{err: errWrite})
+	_, err = w.Write([]byte("x"))
+	assert.ErrorIs(t, err, errWrite)
+	_, err = w.Write([]byte("x"))
+	assert.ErrorIs(t, err, pola.ErrNoTeeTarget)
+}
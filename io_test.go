@@ -1,10 +1,18 @@
 package pola_test
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ipsusila/pola"
 	"github.com/stretchr/testify/assert"
@@ -74,3 +82,138 @@ func TestParseUrl(t *testing.T) {
 		}
 	}
 }
+
+func TestListenDescriptor(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "listen.sock")
+
+	// accept first connection
+	go func() {
+		for !pola.PathExists(sock) {
+			time.Sleep(time.Millisecond)
+		}
+		w, err := pola.WriteCloserFromDescriptor("unix://" + sock)
+		if assert.NoError(t, err) {
+			io.WriteString(w, "hello")
+			w.Close()
+		}
+	}()
+	r, err := pola.ReadCloserFromDescriptor("unix-listen://" + sock)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.NoError(t, r.Close())
+
+	// fan-in
+	sock = filepath.Join(dir, "fanin.sock")
+	chReader := make(chan io.ReadCloser, 1)
+	go func() {
+		r, err := pola.ReadCloserFromDescriptor("unix-listen://" + sock + "?fanin=1")
+		assert.NoError(t, err)
+		chReader <- r
+	}()
+	r = <-chReader
+	for i := range 3 {
+		w, err := pola.WriteCloserFromDescriptor("unix://" + sock)
+		if assert.NoError(t, err) {
+			fmt.Fprintf(w, "line %d\n", i)
+			w.Close()
+		}
+	}
+	lines := make([]string, 0, 3)
+	br := bufio.NewReader(r)
+	for range 3 {
+		line, err := br.ReadString('\n')
+		assert.NoError(t, err)
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	assert.Equal(t, []string{"line 0\n", "line 1\n", "line 2\n"}, lines)
+	assert.NoError(t, r.Close())
+	_, err = r.Read(make([]byte, 1))
+	assert.Error(t, err)
+}
+
+func TestClosersOrder(t *testing.T) {
+	order := []int{}
+	add := func(cs pola.ContextClosers, i int) {
+		cs.Defer(func() error {
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	cs := pola.NewContextClosers()
+	for i := range 3 {
+		add(cs, i)
+	}
+	assert.NoError(t, cs.Close())
+	assert.Equal(t, []int{0, 1, 2}, order)
+
+	order = order[:0]
+	cs = pola.NewContextClosers().SetOrder(pola.CloseLIFO)
+	for i := range 3 {
+		add(cs, i)
+	}
+	assert.NoError(t, cs.Close())
+	assert.Equal(t, []int{2, 1, 0}, order)
+	assert.True(t, cs.Empty())
+}
+
+func TestClosersError(t *testing.T) {
+	errFail := errors.New("fail")
+	cs := pola.NewContextClosers(pola.DevNull).Defer(func() error { return errFail })
+	err := cs.Close()
+	assert.ErrorIs(t, err, errFail)
+	cerr := &pola.CloserError{}
+	if assert.ErrorAs(t, err, &cerr) {
+		assert.Equal(t, 1, cerr.Index)
+		assert.Contains(t, err.Error(), "closer #1 (pola.CloserFunc)")
+	}
+
+	// hanging close
+	block := make(chan struct{})
+	defer close(block)
+	cs = pola.NewContextClosers().SetTimeout(20 * time.Millisecond).Defer(func() error {
+		<-block
+		return nil
+	})
+	assert.ErrorIs(t, cs.Close(), pola.ErrCloseTimeout)
+
+	// cancelled context keep remaining items
+	ctx, cancel := context.WithCancel(context.Background())
+	closed := 0
+	cs = pola.NewContextClosers().SetOrder(pola.CloseLIFO)
+	cs.Defer(func() error { closed++; return nil })
+	cs.Defer(func() error { cancel(); <-block; return nil })
+	assert.ErrorIs(t, cs.CloseContext(ctx), context.Canceled)
+	assert.Equal(t, 1, cs.Len())
+	assert.Equal(t, 0, closed)
+	assert.NoError(t, cs.Close())
+	assert.Equal(t, 1, closed)
+}
+
+type countCloser struct {
+	n atomic.Int32
+}
+
+func (c *countCloser) Close() error {
+	c.n.Add(1)
+	return nil
+}
+
+func TestSafeSyncCloser(t *testing.T) {
+	cc := &countCloser{}
+	sc := pola.SafeSyncCloser(cc)
+	wg := sync.WaitGroup{}
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc.Close()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), cc.n.Load())
+}
@@ -0,0 +1,92 @@
+package pola
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// readPathFile read whole content of file given as path or file:// URL.
+func readPathFile(name string) ([]byte, error) {
+	if u, err := url.Parse(name); err == nil && strings.EqualFold(u.Scheme, "file") {
+		name = u.Path
+	}
+	return os.ReadFile(name)
+}
+
+// TLSConfigFromQuery create tls.Config from URL query options:
+//   - ca: PEM encoded CA certificates used to verify the server
+//   - cert, key: PEM encoded client certificate and private key
+//   - server_name: name used for verification (default to host part of `addr`)
+//   - insecure=1: skip server certificate verification
+//
+// File options accept path or file:// URL.
+func TLSConfigFromQuery(q url.Values, addr string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         q.Get("server_name"),
+		InsecureSkipVerify: ToBool(q.Get("insecure")),
+	}
+	if cfg.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			cfg.ServerName = host
+		}
+	}
+	if ca := q.Get("ca"); ca != "" {
+		pem, err := readPathFile(ca)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", ca)
+		}
+		cfg.RootCAs = pool
+	}
+
+	cert, key := q.Get("cert"), q.Get("key")
+	if cert != "" || key != "" {
+		if cert == "" || key == "" {
+			return nil, errors.New("both `cert` and `key` must be specified")
+		}
+		certPem, err := readPathFile(cert)
+		if err != nil {
+			return nil, err
+		}
+		keyPem, err := readPathFile(key)
+		if err != nil {
+			return nil, err
+		}
+		pair, err := tls.X509KeyPair(certPem, keyPem)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+// tlsFromDescriptor connect to TLS endpoint, e.g.
+// tls://host:port?ca=/etc/ssl/ca.pem or tcp+tls://host:port?insecure=1.
+// See TLSConfigFromQuery for supported options, `reconnect=1` is supported for writing.
+func tlsFromDescriptor(ctx context.Context, u *url.URL, wr bool) (io.ReadWriteCloser, error) {
+	q := u.Query()
+	cfg, err := TLSConfigFromQuery(q, u.Host)
+	if err != nil {
+		return nil, err
+	}
+	d := tls.Dialer{Config: cfg}
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", u.Host)
+	}
+	if wr && ToBool(q.Get("reconnect")) {
+		return reconnectFromDescriptor(ctx, u, dial)
+	}
+	return dial(ctx)
+}
@@ -0,0 +1,129 @@
+package pola_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPem []byte
+	keyPem  []byte
+}
+
+// genCert create certificate signed by parent (self-signed if parent is nil)
+func genCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPem:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func TestTLSDescriptor(t *testing.T) {
+	ca := genCert(t, "test-ca", nil)
+	srv := genCert(t, "localhost", ca)
+	client := genCert(t, "client", ca)
+	dir := t.TempDir()
+	caPem := filepath.Join(dir, "ca.pem")
+	clientPem := filepath.Join(dir, "client.pem")
+	clientKey := filepath.Join(dir, "client.key")
+	assert.NoError(t, os.WriteFile(caPem, ca.certPem, 0o600))
+	assert.NoError(t, os.WriteFile(clientPem, client.certPem, 0o600))
+	assert.NoError(t, os.WriteFile(clientKey, client.keyPem, 0o600))
+
+	srvPair, err := tls.X509KeyPair(srv.certPem, srv.keyPem)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{srvPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	chData := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				data, _ := io.ReadAll(conn)
+				chData <- string(data)
+			}()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	opts := "?ca=" + caPem + "&cert=file://" + clientPem + "&key=" + clientKey
+	for _, scheme := range []string{"tls", "tcp+tls"} {
+		w, err := pola.WriteCloserFromDescriptor(scheme + "://localhost:" + port + opts)
+		if assert.NoError(t, err, scheme) {
+			io.WriteString(w, "secret from "+scheme)
+			assert.NoError(t, w.Close())
+			assert.Equal(t, "secret from "+scheme, <-chData)
+		}
+	}
+
+	// server certificate is not trusted
+	_, err = pola.WriteCloserFromDescriptor("tls://localhost:" + port)
+	assert.Error(t, err)
+
+	// wrong server name
+	_, err = pola.WriteCloserFromDescriptor("tls://127.0.0.1:" + port + opts)
+	assert.Error(t, err)
+
+	_, err = pola.WriteCloserFromDescriptor("tls://localhost:" + port + "?cert=" + clientPem)
+	assert.Error(t, err)
+
+	// only files are accepted
+	assert.NoError(t, writeDescriptor(t, "mem://tls/ca.pem", string(ca.certPem)))
+	_, err = pola.WriteCloserFromDescriptor("tls://localhost:" + port + "?ca=mem://tls/ca.pem")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
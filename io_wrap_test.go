@@ -0,0 +1,176 @@
+package pola_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingReaderWriter(t *testing.T) {
+	data := strings.Repeat("0123456789", 1000)
+
+	cr := pola.NewCountingReader(strings.NewReader(data))
+	cw := pola.NewCountingWriter(&bytes.Buffer{})
+	n, err := io.Copy(cw, cr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(data), n)
+	assert.EqualValues(t, len(data), cr.Count())
+	assert.EqualValues(t, len(data), cw.Count())
+
+	// without fast path
+	cr = pola.NewCountingReader(io.LimitReader(strings.NewReader(data), 100))
+	b, err := io.ReadAll(cr)
+	assert.NoError(t, err)
+	assert.Len(t, b, 100)
+	assert.EqualValues(t, 100, cr.Count())
+}
+
+func TestProgressReader(t *testing.T) {
+	data := strings.Repeat("x", 1000)
+	var last, total int64
+	calls := 0
+	pr := pola.NewProgressReader(strings.NewReader(data), int64(len(data)), func(r, t int64) {
+		last, total = r, t
+		calls++
+	})
+	buf := make([]byte, 100)
+	for {
+		if _, err := pr.Read(buf); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+	assert.EqualValues(t, 1000, last)
+	assert.EqualValues(t, 1000, total)
+	assert.Equal(t, 10, calls)
+}
+
+// sleepClock advance its time instead of waiting
+type sleepClock struct {
+	sync.Mutex
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *sleepClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+func (c *sleepClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	c.slept += d
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestRateLimited(t *testing.T) {
+	// burst equals rate, so 2.5x rate takes 1.5 seconds
+	const rate = 1000
+	data := strings.Repeat("x", 2*rate+rate/2)
+
+	clock := &sleepClock{now: time.Now()}
+	buf := &bytes.Buffer{}
+	n, err := io.Copy(buf, pola.NewRateLimitedReader(strings.NewReader(data), rate).SetClock(clock))
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(data), n)
+	assert.InDelta(t, 1.5, clock.slept.Seconds(), 0.01)
+
+	clock = &sleepClock{now: time.Now()}
+	buf.Reset()
+	w := pola.NewRateLimitedWriter(buf, rate).SetClock(clock)
+	nw, err := w.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), nw)
+	assert.Equal(t, data, buf.String())
+	assert.InDelta(t, 1.5, clock.slept.Seconds(), 0.01)
+
+	// without fast path
+	clock = &sleepClock{now: time.Now()}
+	buf.Reset()
+	r := pola.NewRateLimitedReader(io.LimitReader(strings.NewReader(data), 1500), rate).SetClock(clock)
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Len(t, b, 1500)
+	assert.InDelta(t, 0.5, clock.slept.Seconds(), 0.01)
+}
+
+func TestRateUnlimited(t *testing.T) {
+	data := strings.Repeat("x", 5000)
+	for _, rate := range []int64{0, -1} {
+		r := pola.NewRateLimitedReader(strings.NewReader(data), rate)
+		p := make([]byte, len(data))
+		n, err := r.Read(p)
+		assert.NoError(t, err)
+		assert.Equal(t, len(data), n)
+
+		buf := &bytes.Buffer{}
+		n, err = pola.NewRateLimitedWriter(buf, rate).Write([]byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, len(data), n)
+		assert.Equal(t, data, buf.String())
+	}
+}
+
+func TestRateDescriptorCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := pola.WriteCloserFromDescriptorContext(ctx, "mem://rate-cancel.txt?rate=10")
+	if !assert.NoError(t, err) {
+		return
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	// 100 bytes at 10 bytes/second would take 9 seconds
+	start := time.Now()
+	_, err = w.Write([]byte(strings.Repeat("x", 100)))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	w.Close()
+}
+
+func TestRateDescriptor(t *testing.T) {
+	data := strings.Repeat("x", 1500)
+	desc := "mem://rate.txt?rate=1MB"
+	assert.NoError(t, writeDescriptor(t, desc, data))
+
+	rc, err := pola.ReadCloserFromDescriptor(desc)
+	assert.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, data, string(b))
+
+	_, err = pola.ReadCloserFromDescriptor("mem://rate.txt?rate=fast")
+	assert.Error(t, err)
+}
+
+func TestRateHTTPQuery(t *testing.T) {
+	// generic options are not taken from http query
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	rc, err := pola.ReadCloserFromDescriptor(srv.URL + "/x?compress=none&rate=high")
+	if assert.NoError(t, err) {
+		b, _ := io.ReadAll(rc)
+		assert.Equal(t, "ok", string(b))
+		assert.NoError(t, rc.Close())
+	}
+	assert.Equal(t, "compress=none&rate=high", query)
+}
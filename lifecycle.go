@@ -0,0 +1,282 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDependencyCycle = errors.New("dependency cycle")
+	ErrAlreadyStarted  = errors.New("already started")
+)
+
+// Hook is a component registered in the Lifecycle.
+type Hook struct {
+	// Name identify the hook, it must be unique within Lifecycle.
+	Name string
+
+	// DependsOn list names of hooks which must be started before this one
+	// (and stopped after this one).
+	DependsOn []string
+
+	// OnStart is called when the Lifecycle starts. It should not block.
+	OnStart func(ctx context.Context) error
+
+	// OnStop is called when the Lifecycle stops.
+	OnStop func(ctx context.Context) error
+
+	// StopTimeout override Lifecycle.StopTimeout for this hook.
+	StopTimeout time.Duration
+}
+
+// CloserHook create Hook which close `c` when stopped.
+func CloserHook(name string, c io.Closer, dependsOn ...string) Hook {
+	return Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStop: func(ctx context.Context) error {
+			return c.Close()
+		},
+	}
+}
+
+// RunnerHook create Hook which execute Runner in background when started.
+// When stopped, the Runner context is canceled and the hook waits
+// until the Runner returns (or the stop context is done).
+// When started by Lifecycle.RunContext, Runner failure stops the Lifecycle.
+func RunnerHook(name string, r Runner, dependsOn ...string) Hook {
+	var cancel context.CancelFunc
+	var chErr chan error
+	reported := false
+	return Hook{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			fail, _ := ctx.Value(lifecycleFailKey{}).(func(string, error))
+			var cctx context.Context
+			cctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			chErr = make(chan error, 1)
+			reported = false
+			go func() {
+				err := r.RunContext(cctx)
+				if err != nil && cctx.Err() == nil && fail != nil {
+					fail(name, err)
+					reported = true
+				}
+				chErr <- err
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case err := <-chErr:
+				if reported || errors.Is(err, context.Canceled) {
+					return nil
+				}
+				return err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Lifecycle starts registered hooks in dependency order
+// and stops them in reverse order. Lifecycle is a Runner,
+// so it can be driven by InterruptibleContext.
+type Lifecycle struct {
+	mu sync.Mutex
+
+	// StopTimeout is the default timeout for each OnStop hook.
+	// Zero means no timeout.
+	StopTimeout time.Duration
+
+	hooks   []Hook
+	started []Hook
+	running bool
+}
+
+// NewLifecycle create lifecycle manager with default stop timeout.
+func NewLifecycle(stopTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{StopTimeout: stopTimeout}
+}
+
+// Append register hook. Hooks can not be appended after Lifecycle is started.
+func (l *Lifecycle) Append(h Hook) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running {
+		return ErrAlreadyStarted
+	}
+	for _, hi := range l.hooks {
+		if hi.Name == h.Name {
+			return fmt.Errorf("hook: %s, %w", h.Name, ErrDuplicateEntry)
+		}
+	}
+	l.hooks = append(l.hooks, h)
+	return nil
+}
+
+// order sort hooks so that dependencies come first.
+// Hooks without dependency relation keep registration order.
+func (l *Lifecycle) order() ([]Hook, error) {
+	idx := make(map[string]int, len(l.hooks))
+	for i, h := range l.hooks {
+		idx[h.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(l.hooks))
+	sorted := make([]Hook, 0, len(l.hooks))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("hook: %s, %w", l.hooks[i].Name, ErrDependencyCycle)
+		}
+		state[i] = visiting
+		for _, dep := range l.hooks[i].DependsOn {
+			j, ok := idx[dep]
+			if !ok {
+				return fmt.Errorf("hook: %s depends on %s, %w", l.hooks[i].Name, dep, ErrEntryDoesNotExists)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		sorted = append(sorted, l.hooks[i])
+		return nil
+	}
+	for i := range l.hooks {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// Start execute OnStart hooks in dependency order.
+// If one of the hooks fails, already started hooks are stopped in reverse order.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running {
+		return ErrAlreadyStarted
+	}
+	hooks, err := l.order()
+	if err != nil {
+		return err
+	}
+
+	l.running = true
+	for _, h := range hooks {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				err = fmt.Errorf("start %s: %w", h.Name, err)
+				return errors.Join(err, l.stop(context.WithoutCancel(ctx)))
+			}
+		}
+		l.started = append(l.started, h)
+	}
+
+	return nil
+}
+
+// Stop execute OnStop hooks of started components in reverse order.
+// Each hook get its own context limited by its stop timeout.
+// All hooks are stopped even if some of them fail, and errors are joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stop(ctx)
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	var errs error
+	for i := len(l.started) - 1; i >= 0; i-- {
+		h := l.started[i]
+		if h.OnStop == nil {
+			continue
+		}
+		timeout := h.StopTimeout
+		if timeout <= 0 {
+			timeout = l.StopTimeout
+		}
+		if err := runStopHook(ctx, h, timeout); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("stop %s: %w", h.Name, err))
+		}
+	}
+	l.started = nil
+	l.running = false
+
+	return errs
+}
+
+func runStopHook(ctx context.Context, h Hook, timeout time.Duration) error {
+	if timeout <= 0 {
+		return h.OnStop(ctx)
+	}
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// do not wait for hook which ignores its context
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- h.OnStop(cctx)
+	}()
+	select {
+	case err := <-chErr:
+		return err
+	case <-cctx.Done():
+		return cctx.Err()
+	}
+}
+
+// lifecycleFailKey is context key of function which report hook failure
+type lifecycleFailKey struct{}
+
+// RunContext start all hooks, wait until ctx is done (or one of RunnerHook fails)
+// and then stop them. Runner failure is returned together with stop errors.
+// Stop hooks receive a context which is not canceled together with ctx.
+func (l *Lifecycle) RunContext(ctx context.Context) error {
+	cctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var mu sync.Mutex
+	var failErr error
+	fail := func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failErr == nil {
+			failErr = fmt.Errorf("run %s: %w", name, err)
+			cancel(failErr)
+		}
+	}
+	if err := l.Start(context.WithValue(ctx, lifecycleFailKey{}, fail)); err != nil {
+		return err
+	}
+	<-cctx.Done()
+
+	err := l.Stop(context.WithoutCancel(ctx))
+	mu.Lock()
+	defer mu.Unlock()
+
+	return errors.Join(failErr, err)
+}
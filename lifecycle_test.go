@@ -0,0 +1,108 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	var events []string
+	hook := func(name string, deps ...string) pola.Hook {
+		return pola.Hook{
+			Name:      name,
+			DependsOn: deps,
+			OnStart: func(ctx context.Context) error {
+				events = append(events, "start:"+name)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				events = append(events, "stop:"+name)
+				return nil
+			},
+		}
+	}
+
+	lc := pola.NewLifecycle(time.Second)
+	assert.NoError(t, lc.Append(hook("http", "db", "cache")))
+	assert.NoError(t, lc.Append(hook("cache", "db")))
+	assert.NoError(t, lc.Append(hook("db")))
+	assert.ErrorIs(t, lc.Append(hook("db")), pola.ErrDuplicateEntry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, lc.RunContext(ctx))
+	assert.Equal(t, []string{
+		"start:db", "start:cache", "start:http",
+		"stop:http", "stop:cache", "stop:db",
+	}, events)
+
+	// cycle and unknown dependency
+	lc = pola.NewLifecycle(0)
+	lc.Append(hook("a", "b"))
+	lc.Append(hook("b", "a"))
+	assert.ErrorIs(t, lc.Start(context.Background()), pola.ErrDependencyCycle)
+
+	lc = pola.NewLifecycle(0)
+	lc.Append(hook("a", "x"))
+	assert.ErrorIs(t, lc.Start(context.Background()), pola.ErrEntryDoesNotExists)
+}
+
+func TestLifecycleFailure(t *testing.T) {
+	var slowStopped atomic.Bool
+	errStart := errors.New("start failed")
+
+	lc := pola.NewLifecycle(20 * time.Millisecond)
+	lc.Append(pola.Hook{
+		Name: "slow",
+		OnStop: func(ctx context.Context) error {
+			slowStopped.Store(true)
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+	lc.Append(pola.RunnerHook("worker", pola.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})))
+	lc.Append(pola.CloserHook("null", pola.DevNull))
+	lc.Append(pola.Hook{
+		Name: "broken",
+		OnStart: func(ctx context.Context) error {
+			return errStart
+		},
+	})
+
+	err := lc.Start(context.Background())
+	assert.ErrorIs(t, err, errStart)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, slowStopped.Load())
+}
+
+func TestLifecycleRunnerFailure(t *testing.T) {
+	errRun := errors.New("runner failed")
+	stopped := atomic.Bool{}
+	lc := pola.NewLifecycle(time.Second)
+	assert.NoError(t, lc.Append(pola.Hook{
+		Name: "db",
+		OnStop: func(ctx context.Context) error {
+			stopped.Store(true)
+			return nil
+		},
+	}))
+	assert.NoError(t, lc.Append(pola.RunnerHook("worker", pola.RunnerFunc(func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return errRun
+	}), "db")))
+
+	// runner failure stops the lifecycle without canceling ctx
+	err := lc.RunContext(context.Background())
+	assert.ErrorIs(t, err, errRun)
+	assert.Contains(t, err.Error(), "run worker")
+	assert.True(t, stopped.Load())
+}
@@ -0,0 +1,100 @@
+package pola
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunnerMiddleware wraps Runner to add behaviour around it.
+type RunnerMiddleware func(Runner) Runner
+
+// Chain wraps `r` with given middlewares.
+// The first middleware is the outermost one, i.e.
+// Chain(r, a, b) is equivalent to a(b(r)).
+func Chain(r Runner, mws ...RunnerMiddleware) Runner {
+	for i := len(mws) - 1; i >= 0; i-- {
+		r = mws[i](r)
+	}
+	return r
+}
+
+// WithTimeout limit the execution time of the Runner.
+func WithTimeout(d time.Duration) RunnerMiddleware {
+	return func(r Runner) Runner {
+		return RunnerFunc(func(ctx context.Context) error {
+			cctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return r.RunContext(cctx)
+		})
+	}
+}
+
+// WithRecover convert panic inside the Runner into *PanicError,
+// which contains the panic value and the stack trace.
+func WithRecover() RunnerMiddleware {
+	return func(r Runner) Runner {
+		return RunnerFunc(func(ctx context.Context) error {
+			return runRecover(ctx, r)
+		})
+	}
+}
+
+// WithLogging log start, stop, duration and error of the Runner.
+// If logger is nil, slog.Default() is used.
+func WithLogging(logger *slog.Logger, name string) RunnerMiddleware {
+	return func(r Runner) Runner {
+		return RunnerFunc(func(ctx context.Context) error {
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			l.InfoContext(ctx, "runner started", "name", name)
+			start := time.Now()
+			err := r.RunContext(ctx)
+			if err != nil {
+				l.ErrorContext(ctx, "runner stopped", "name", name, "duration", time.Since(start), "error", err)
+			} else {
+				l.InfoContext(ctx, "runner stopped", "name", name, "duration", time.Since(start))
+			}
+			return err
+		})
+	}
+}
+
+// WithRetry re-run the Runner up to `retries` times when it returns error,
+// waiting `backoff` between attempts. It stops when the context is canceled,
+// and return the last error. See Supervise for more control.
+func WithRetry(retries int, backoff time.Duration) RunnerMiddleware {
+	return func(r Runner) Runner {
+		return RunnerFunc(func(ctx context.Context) error {
+			err := r.RunContext(ctx)
+			for i := 0; i < retries && err != nil && ctx.Err() == nil; i++ {
+				if backoff > 0 {
+					tm := time.NewTimer(backoff)
+					select {
+					case <-ctx.Done():
+						tm.Stop()
+						return err
+					case <-tm.C:
+					}
+				}
+				err = r.RunContext(ctx)
+			}
+			return err
+		})
+	}
+}
+
+// WithMetrics call `observe` with the duration and result of every run,
+// e.g. to feed a histogram or an error counter.
+func WithMetrics(observe func(d time.Duration, err error)) RunnerMiddleware {
+	return func(r Runner) Runner {
+		return RunnerFunc(func(ctx context.Context) error {
+			start := time.Now()
+			err := r.RunContext(ctx)
+			observe(time.Since(start), err)
+			return err
+		})
+	}
+}
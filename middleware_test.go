@@ -0,0 +1,100 @@
+package pola_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var order []string
+	mw := func(name string) pola.RunnerMiddleware {
+		return func(r pola.Runner) pola.Runner {
+			return pola.RunnerFunc(func(ctx context.Context) error {
+				order = append(order, name)
+				return r.RunContext(ctx)
+			})
+		}
+	}
+
+	errRun := errors.New("run failed")
+	calls := 0
+	buf := bytes.Buffer{}
+	var observed []error
+
+	r := pola.Chain(pola.RunnerFunc(func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return errRun
+	}),
+		mw("a"),
+		mw("b"),
+		pola.WithLogging(slog.New(slog.NewTextHandler(&buf, nil)), "job"),
+		pola.WithMetrics(func(d time.Duration, err error) {
+			observed = append(observed, err)
+		}),
+		pola.WithRetry(2, time.Millisecond),
+		pola.WithRecover(),
+	)
+	err := r.RunContext(context.Background())
+	assert.ErrorIs(t, err, errRun)
+	assert.NotErrorIs(t, err, pola.ErrTooManyRestarts)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []string{"a", "b"}, order)
+	assert.Len(t, observed, 1)
+	assert.Contains(t, buf.String(), "runner started")
+	assert.Contains(t, buf.String(), "name=job")
+
+	var pe *pola.PanicError
+	err = pola.WithRecover()(pola.RunnerFunc(func(ctx context.Context) error {
+		panic(errRun)
+	})).RunContext(context.Background())
+	assert.ErrorAs(t, err, &pe)
+	assert.ErrorIs(t, err, errRun)
+
+	err = pola.WithTimeout(time.Millisecond)(pola.RunnerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})).RunContext(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWithRetry(t *testing.T) {
+	errRun := errors.New("run failed")
+	calls := 0
+	start := time.Now()
+	err := pola.WithRetry(3, 0)(pola.RunnerFunc(func(ctx context.Context) error {
+		calls++
+		return fmt.Errorf("attempt %d: %w", calls, errRun)
+	})).RunContext(context.Background())
+	assert.EqualError(t, err, "attempt 4: run failed")
+	assert.Equal(t, 4, calls)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// panic is not recovered
+	assert.Panics(t, func() {
+		pola.WithRetry(3, 0)(pola.RunnerFunc(func(ctx context.Context) error {
+			panic(errRun)
+		})).RunContext(context.Background())
+	})
+
+	// canceled context stop waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = pola.WithRetry(3, time.Hour)(pola.RunnerFunc(func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errRun
+	})).RunContext(ctx)
+	assert.ErrorIs(t, err, errRun)
+	assert.Equal(t, 1, calls)
+}
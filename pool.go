@@ -0,0 +1,138 @@
+package pola
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// Pool is typed wrapper of sync.Pool.
+// To avoid allocation on Put, T should be pointer-like type.
+type Pool[T any] struct {
+	p sync.Pool
+}
+
+// NewPool create pool, where `newFn` create new item when pool is empty.
+func NewPool[T any](newFn func() T) *Pool[T] {
+	return &Pool[T]{
+		p: sync.Pool{
+			New: func() any {
+				return newFn()
+			},
+		},
+	}
+}
+
+// Get item from pool or create new one.
+func (p *Pool[T]) Get() T {
+	return p.p.Get().(T)
+}
+
+// Put item back to the pool.
+func (p *Pool[T]) Put(v T) {
+	p.p.Put(v)
+}
+
+const (
+	minBytesClassShift = 9       // 512 bytes
+	defBytesPoolMax    = 1 << 20 // 1MB
+	copyBufferSize     = 32 * 1024
+)
+
+// BytesPoolStats is usage statistic of BytesPool.
+type BytesPoolStats struct {
+	// Hits is number of Get served from pool
+	Hits uint64
+	// Misses is number of Get which allocate new buffer
+	Misses uint64
+	// Dropped is number of Put which discard the buffer
+	Dropped uint64
+}
+
+// BytesPool is []byte pool with power-of-two size classes.
+// Buffers larger than maximum size are never pooled.
+type BytesPool struct {
+	maxSize int
+	classes []sync.Pool
+	// recycled *[]byte holders, so Put does not allocate
+	holders sync.Pool
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewSizedBytesPool create pool with size classes from 512 bytes up to `maxSize`,
+// rounded up to power of two. If maxSize <= 0, it defaults to 1MB.
+func NewSizedBytesPool(maxSize int) *BytesPool {
+	if maxSize <= 0 {
+		maxSize = defBytesPoolMax
+	}
+	nc := max(bits.Len(uint(maxSize-1))-minBytesClassShift+1, 1)
+	return &BytesPool{
+		maxSize: 1 << (minBytesClassShift + nc - 1),
+		classes: make([]sync.Pool, nc),
+		holders: sync.Pool{
+			New: func() any {
+				return new([]byte)
+			},
+		},
+	}
+}
+
+// classOf return size class index for buffer of n bytes
+func classOf(n int) int {
+	if n <= 1<<minBytesClassShift {
+		return 0
+	}
+	return bits.Len(uint(n-1)) - minBytesClassShift
+}
+
+// Get return buffer with length n, and capacity of its size class.
+// Buffer larger than maximum size is allocated directly.
+func (bp *BytesPool) Get(n int) []byte {
+	if n > bp.maxSize {
+		bp.misses.Add(1)
+		return make([]byte, n)
+	}
+	c := classOf(n)
+	if v := bp.classes[c].Get(); v != nil {
+		bp.hits.Add(1)
+		h := v.(*[]byte)
+		b := *h
+		*h = nil
+		bp.holders.Put(h)
+		return b[:n]
+	}
+	bp.misses.Add(1)
+	return make([]byte, n, 1<<(minBytesClassShift+c))
+}
+
+// Put return buffer to the pool. Buffer which capacity is not
+// one of the size classes (e.g. too large) is dropped.
+func (bp *BytesPool) Put(b []byte) {
+	c := cap(b)
+	if c < 1<<minBytesClassShift || c > bp.maxSize || c&(c-1) != 0 {
+		bp.dropped.Add(1)
+		return
+	}
+	h := bp.holders.Get().(*[]byte)
+	*h = b[:c]
+	bp.classes[classOf(c)].Put(h)
+}
+
+// MaxSize return largest pooled buffer size.
+func (bp *BytesPool) MaxSize() int {
+	return bp.maxSize
+}
+
+// Stats return usage statistic.
+func (bp *BytesPool) Stats() BytesPoolStats {
+	return BytesPoolStats{
+		Hits:    bp.hits.Load(),
+		Misses:  bp.misses.Load(),
+		Dropped: bp.dropped.Load(),
+	}
+}
+
+// package wide buffer pool, used by copy helpers
+var bytesPool = NewSizedBytesPool(0)
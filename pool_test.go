@@ -0,0 +1,61 @@
+package pola_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	p := pola.NewPool(func() *bytes.Buffer { return &bytes.Buffer{} })
+	b := p.Get()
+	assert.NotNil(t, b)
+	b.WriteString("x")
+	b.Reset()
+	p.Put(b)
+	assert.NotNil(t, p.Get())
+}
+
+func TestBytesPool(t *testing.T) {
+	bp := pola.NewSizedBytesPool(3000)
+	assert.Equal(t, 4096, bp.MaxSize())
+
+	b := bp.Get(10)
+	assert.Len(t, b, 10)
+	assert.Equal(t, 512, cap(b))
+	b = bp.Get(513)
+	assert.Len(t, b, 513)
+	assert.Equal(t, 1024, cap(b))
+	b = bp.Get(4096)
+	assert.Equal(t, 4096, cap(b))
+	bp.Put(b)
+
+	// too large, not pooled
+	b = bp.Get(5000)
+	assert.Len(t, b, 5000)
+	bp.Put(b)
+	bp.Put(make([]byte, 100))
+	bp.Put(make([]byte, 600))
+
+	st := bp.Stats()
+	assert.EqualValues(t, 4, st.Misses+st.Hits)
+	assert.EqualValues(t, 3, st.Dropped)
+}
+
+func TestDevNullReadFrom(t *testing.T) {
+	data := strings.Repeat("x", 100000)
+	n, err := pola.DevNull.ReadFrom(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(data), n)
+}
+
+func BenchmarkBytesPool(b *testing.B) {
+	bp := pola.NewSizedBytesPool(0)
+	for b.Loop() {
+		buf := bp.Get(32 * 1024)
+		bp.Put(buf)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -161,3 +162,109 @@ func (r *syncMapRegistry[K, V]) MustGet(k K) V {
 	}
 	return v
 }
+
+type cowRegistry[K comparable, V any] struct {
+	sync.Mutex
+	m atomic.Pointer[map[K]V]
+}
+
+// NewCowRegistry create copy-on-write registry backed by an immutable map.
+// Reads (Get, MustGet, Exists, Map) do not take any lock,
+// while writes rebuild the whole map. Use it when entries are registered
+// once and looked up many times. This registry is safe for concurrent usage.
+func NewCowRegistry[K comparable, V any]() Registry[K, V] {
+	return newCowRegistry[K, V]()
+}
+
+func newCowRegistry[K comparable, V any]() *cowRegistry[K, V] {
+	r := &cowRegistry[K, V]{}
+	m := make(map[K]V)
+	r.m.Store(&m)
+	return r
+}
+
+func (r *cowRegistry[K, V]) load() map[K]V {
+	return *r.m.Load()
+}
+
+// store must be called with the write lock held.
+func (r *cowRegistry[K, V]) store(k K, v V) {
+	old := r.load()
+	m := make(map[K]V, len(old)+1)
+	maps.Copy(m, old)
+	m[k] = v
+	r.m.Store(&m)
+}
+
+// remove entry, return false if it does not exist.
+func (r *cowRegistry[K, V]) remove(k K) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	old := r.load()
+	if _, ok := old[k]; !ok {
+		return false
+	}
+	m := maps.Clone(old)
+	delete(m, k)
+	r.m.Store(&m)
+	return true
+}
+
+func (r *cowRegistry[K, V]) Map() map[K]V {
+	m := r.load()
+	if len(m) == 0 {
+		return nil
+	}
+	d := make(map[K]V, len(m))
+	maps.Copy(d, m)
+
+	return d
+}
+
+func (r *cowRegistry[K, V]) Set(k K, v V) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.store(k, v)
+}
+
+func (r *cowRegistry[K, V]) Register(k K, v V) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.load()[k]; ok {
+		return ErrDuplicateEntry
+	}
+	r.store(k, v)
+	return nil
+}
+
+func (r *cowRegistry[K, V]) MustRegister(k K, v V) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.load()[k]; ok {
+		panic(fmt.Sprintf("duplicate entry `%v`", k))
+	}
+	r.store(k, v)
+}
+
+func (r *cowRegistry[K, V]) Exists(k K) bool {
+	_, ok := r.load()[k]
+	return ok
+}
+func (r *cowRegistry[K, V]) Get(k K) (V, error) {
+	v, ok := r.load()[k]
+	if ok {
+		return v, nil
+	}
+	return v, fmt.Errorf("key: %v, %w", k, ErrEntryDoesNotExists)
+}
+func (r *cowRegistry[K, V]) MustGet(k K) V {
+	v, ok := r.load()[k]
+	if !ok {
+		panic(fmt.Sprintf("entry `%v` does not exists", k))
+	}
+	return v
+}
@@ -0,0 +1,107 @@
+package pola_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	regs := map[string]pola.Registry[string, int]{
+		"map":  pola.NewRegistry[string, int](),
+		"sync": pola.NewSyncRegistry[string, int](),
+		"cow":  pola.NewCowRegistry[string, int](),
+	}
+	for name, reg := range regs {
+		assert.Nil(t, reg.Map(), name)
+		assert.NoError(t, reg.Register("one", 1), name)
+		assert.ErrorIs(t, reg.Register("one", 11), pola.ErrDuplicateEntry, name)
+		assert.Panics(t, func() { reg.MustRegister("one", 11) }, name)
+		reg.MustRegister("two", 2)
+		reg.Set("three", 3)
+
+		assert.True(t, reg.Exists("two"), name)
+		assert.False(t, reg.Exists("four"), name)
+		v, err := reg.Get("three")
+		assert.NoError(t, err, name)
+		assert.Equal(t, 3, v, name)
+		_, err = reg.Get("four")
+		assert.ErrorIs(t, err, pola.ErrEntryDoesNotExists, name)
+		assert.Panics(t, func() { reg.MustGet("four") }, name)
+		assert.Equal(t, 1, reg.MustGet("one"), name)
+
+		// returned map is a copy
+		m := reg.Map()
+		assert.Len(t, m, 3, name)
+		m["four"] = 4
+		assert.False(t, reg.Exists("four"), name)
+	}
+}
+
+func TestCowRegistryConcurrent(t *testing.T) {
+	reg := pola.NewCowRegistry[int, int]()
+	wg := sync.WaitGroup{}
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				reg.Set(i*100+j, j)
+				reg.Exists(j)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, reg.Map(), 800)
+}
+
+func benchmarkRegistryGet(b *testing.B, reg pola.Registry[string, int]) {
+	keys := make([]string, 64)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		reg.Set(keys[i], i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := reg.Get(keys[i%len(keys)]); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkRegistryGet(b *testing.B) {
+	b.Run("map", func(b *testing.B) {
+		benchmarkRegistryGet(b, pola.NewRegistry[string, int]())
+	})
+	b.Run("sync", func(b *testing.B) {
+		benchmarkRegistryGet(b, pola.NewSyncRegistry[string, int]())
+	})
+	b.Run("cow", func(b *testing.B) {
+		benchmarkRegistryGet(b, pola.NewCowRegistry[string, int]())
+	})
+}
+
+func BenchmarkRegistryMap(b *testing.B) {
+	regs := map[string]pola.Registry[int, int]{
+		"map":  pola.NewRegistry[int, int](),
+		"sync": pola.NewSyncRegistry[int, int](),
+		"cow":  pola.NewCowRegistry[int, int](),
+	}
+	for name, reg := range regs {
+		for i := range 64 {
+			reg.Set(i, i)
+		}
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				_ = reg.Map()
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package pola
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Clock provides current time and timer channel.
+// It is used by scheduled runners, and can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+// SystemClock is Clock backed by package time.
+var SystemClock Clock = systemClock{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// OverlapPolicy determines what happens when a scheduled run
+// is due while the previous one is still running.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skip the due run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue queue the due run and execute it once the previous run returns.
+	OverlapQueue
+)
+
+// ScheduleOptions configure scheduled runners created by Every and Cron.
+type ScheduleOptions struct {
+	Overlap OverlapPolicy
+
+	// Clock default to SystemClock.
+	Clock Clock
+
+	// OnError, if not nil, receive errors returned by the inner Runner
+	// and the schedule continues. Otherwise the first error stops the schedule
+	// and is returned from RunContext.
+	OnError func(error)
+}
+
+type scheduled struct {
+	r    Runner
+	next func(time.Time) time.Time
+	opts ScheduleOptions
+}
+
+// Every create Runner which execute `r` every interval until the context is canceled.
+// The interval is converted using ToDuration, so it can be time.Duration,
+// duration string (e.g. "5m") or number of seconds.
+func Every(interval any, r Runner, opts ...ScheduleOptions) (Runner, error) {
+	d, ok := ToDuration(interval)
+	if !ok || d <= 0 {
+		return nil, fmt.Errorf("invalid interval: %v", interval)
+	}
+	return newScheduled(r, func(t time.Time) time.Time {
+		return t.Add(d)
+	}, opts), nil
+}
+
+// Cron create Runner which execute `r` according to the cron spec
+// (see ParseCron) until the context is canceled.
+func Cron(spec string, r Runner, opts ...ScheduleOptions) (Runner, error) {
+	cs, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	return newScheduled(r, cs.Next, opts), nil
+}
+
+func newScheduled(r Runner, next func(time.Time) time.Time, opts []ScheduleOptions) *scheduled {
+	s := &scheduled{r: r, next: next}
+	if len(opts) > 0 {
+		s.opts = opts[0]
+	}
+	if s.opts.Clock == nil {
+		s.opts.Clock = SystemClock
+	}
+	return s
+}
+
+func (s *scheduled) RunContext(ctx context.Context) error {
+	clock := s.opts.Clock
+	chDone := make(chan error, 1)
+	running := false
+	pending := 0
+
+	start := func() {
+		running = true
+		go func() {
+			chDone <- s.r.RunContext(ctx)
+		}()
+	}
+	schedule := func(now time.Time) <-chan time.Time {
+		next := s.next(now)
+		if next.IsZero() {
+			return nil
+		}
+		return clock.After(next.Sub(clock.Now()))
+	}
+
+	tick := schedule(clock.Now())
+	for {
+		if tick == nil && !running {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if running {
+				<-chDone
+			}
+			return nil
+		case now := <-tick:
+			tick = schedule(now)
+			if !running {
+				start()
+			} else if s.opts.Overlap == OverlapQueue {
+				pending++
+			}
+		case err := <-chDone:
+			running = false
+			if err != nil && ctx.Err() == nil {
+				if s.opts.OnError == nil {
+					return err
+				}
+				s.opts.OnError(err)
+			}
+			if pending > 0 && ctx.Err() == nil {
+				pending--
+				start()
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	added  chan struct{}
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, added: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	c.added <- struct{}{}
+	return ch
+}
+
+// BlockUntilTimer wait until a new timer is registered.
+func (c *fakeClock) BlockUntilTimer() {
+	<-c.added
+}
+
+// Advance wait for a timer to be registered, then move the clock forward.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.BlockUntilTimer()
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	n := 0
+	for _, tm := range c.timers {
+		if !tm.at.After(c.now) {
+			tm.ch <- c.now
+		} else {
+			c.timers[n] = tm
+			n++
+		}
+	}
+	c.timers = c.timers[:n]
+}
+
+func TestCron(t *testing.T) {
+	loc := time.UTC
+	base := time.Date(2024, 1, 31, 10, 30, 15, 0, loc)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 31, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 45, 0, 0, loc)},
+		{"0 9-17/2 * * mon-fri", time.Date(2024, 1, 31, 11, 0, 0, 0, loc)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, loc)},
+		{"0 0 1 * 7", time.Date(2024, 2, 1, 0, 0, 0, 0, loc)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, loc)},
+		{"@weekly", time.Date(2024, 2, 4, 0, 0, 0, 0, loc)},
+		{"5,10 12 * * *", time.Date(2024, 1, 31, 12, 5, 0, 0, loc)},
+	}
+	for _, tc := range tests {
+		cs, err := pola.ParseCron(tc.spec)
+		if assert.NoError(t, err, tc.spec) {
+			assert.Equal(t, tc.next, cs.Next(base), tc.spec)
+		}
+	}
+
+	invalids := []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"}
+	for _, spec := range invalids {
+		_, err := pola.ParseCron(spec)
+		assert.ErrorIs(t, err, pola.ErrInvalidCronSpec, spec)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	_, err := pola.Every("abc", pola.RunnerFunc(nil))
+	assert.Error(t, err)
+
+	clock := newFakeClock(time.Now())
+	var runs atomic.Int32
+	chRun := make(chan struct{})
+	r, err := pola.Every("1m", pola.RunnerFunc(func(ctx context.Context) error {
+		runs.Add(1)
+		chRun <- struct{}{}
+		return nil
+	}), pola.ScheduleOptions{Clock: clock})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- r.RunContext(ctx)
+	}()
+	for range 3 {
+		clock.Advance(time.Minute)
+		<-chRun
+	}
+	cancel()
+	assert.NoError(t, <-chErr)
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestEveryOverlap(t *testing.T) {
+	errRun := errors.New("run failed")
+	for _, policy := range []pola.OverlapPolicy{pola.OverlapSkip, pola.OverlapQueue} {
+		clock := newFakeClock(time.Now())
+		var runs atomic.Int32
+		release := make(chan struct{})
+		r, _ := pola.Every(time.Second, pola.RunnerFunc(func(ctx context.Context) error {
+			runs.Add(1)
+			<-release
+			return errRun
+		}), pola.ScheduleOptions{Clock: clock, Overlap: policy, OnError: func(err error) {}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		chErr := make(chan error, 1)
+		go func() {
+			chErr <- r.RunContext(ctx)
+		}()
+
+		// first run starts, next two are due while it is still running
+		for range 3 {
+			clock.Advance(time.Second)
+		}
+		clock.BlockUntilTimer()
+		release <- struct{}{}
+		if policy == pola.OverlapQueue {
+			release <- struct{}{}
+			release <- struct{}{}
+			assert.Equal(t, int32(3), runs.Load())
+		} else {
+			assert.Equal(t, int32(1), runs.Load())
+		}
+		cancel()
+		assert.NoError(t, <-chErr)
+	}
+
+	// without OnError, first error stops the schedule
+	clock := newFakeClock(time.Now())
+	r, _ := pola.Cron("* * * * *", pola.RunnerFunc(func(ctx context.Context) error {
+		return errRun
+	}), pola.ScheduleOptions{Clock: clock})
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- r.RunContext(context.Background())
+	}()
+	clock.Advance(time.Minute)
+	assert.ErrorIs(t, <-chErr, errRun)
+}
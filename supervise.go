@@ -0,0 +1,167 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"time"
+)
+
+var (
+	ErrTooManyRestarts = errors.New("too many restarts")
+)
+
+// PanicError is returned when a panic is recovered from a Runner.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap return the panic value if it is an error.
+func (p *PanicError) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// runRecover execute runner and convert panic into *PanicError.
+func runRecover(ctx context.Context, r Runner) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return r.RunContext(ctx)
+}
+
+// RestartEvent describes a restart performed by supervisor.
+type RestartEvent struct {
+	// Restart is the restart number within the current window, starting from 1.
+	Restart int
+	// Err is the error returned by the Runner.
+	Err error
+	// Backoff is the delay before the Runner is started again.
+	Backoff time.Duration
+}
+
+// SupervisePolicy controls how Supervise restarts a failing Runner.
+type SupervisePolicy struct {
+	// MaxRestarts is the maximum number of restarts within Window.
+	// Zero or negative means unlimited.
+	MaxRestarts int
+
+	// Window is the period in which restarts are counted.
+	// Zero means restarts are counted for the whole lifetime.
+	Window time.Duration
+
+	// MinBackoff is the delay before the first restart (default 100ms).
+	MinBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the delay (default 30s).
+	MaxBackoff time.Duration
+
+	// Multiplier is the backoff growth factor (default 2).
+	Multiplier float64
+
+	// Jitter randomizes the backoff by +/- the given fraction (0..1).
+	Jitter float64
+
+	// OnRestart, if not nil, is called before each restart.
+	OnRestart func(RestartEvent)
+}
+
+func (p SupervisePolicy) backoff(restart int) time.Duration {
+	minb := p.MinBackoff
+	if minb <= 0 {
+		minb = 100 * time.Millisecond
+	}
+	maxb := p.MaxBackoff
+	if maxb <= 0 {
+		maxb = 30 * time.Second
+	}
+	mul := p.Multiplier
+	if mul < 1 {
+		mul = 2
+	}
+
+	d := float64(minb)
+	for i := 1; i < restart && d < float64(maxb); i++ {
+		d *= mul
+	}
+	d = min(d, float64(maxb))
+	if p.Jitter > 0 {
+		j := min(p.Jitter, 1)
+		d += d * j * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+type supervisor struct {
+	r      Runner
+	policy SupervisePolicy
+}
+
+// Supervise wraps Runner and restarts it whenever RunContext returns an error,
+// waiting with exponential backoff (and jitter) between restarts.
+// Panics are recovered and treated as *PanicError.
+// The supervisor returns when the Runner returns nil, when the context is canceled
+// or when the number of restarts within window exceeds policy.MaxRestarts
+// (ErrTooManyRestarts joined with the last error).
+func Supervise(r Runner, policy SupervisePolicy) Runner {
+	return &supervisor{r: r, policy: policy}
+}
+
+func (s *supervisor) RunContext(ctx context.Context) error {
+	// timestamps are only kept when counting within window
+	var restarts []time.Time
+	count := 0
+	for {
+		err := runRecover(ctx, s.r)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		// count restarts within window
+		if w := s.policy.Window; w > 0 {
+			now := time.Now()
+			n := 0
+			for _, tm := range restarts {
+				if now.Sub(tm) < w {
+					restarts[n] = tm
+					n++
+				}
+			}
+			restarts = append(restarts[:n], now)
+			count = len(restarts)
+		} else {
+			count++
+		}
+		if s.policy.MaxRestarts > 0 && count > s.policy.MaxRestarts {
+			return errors.Join(ErrTooManyRestarts, err)
+		}
+
+		ev := RestartEvent{
+			Restart: count,
+			Err:     err,
+			Backoff: s.policy.backoff(count),
+		}
+		if s.policy.OnRestart != nil {
+			s.policy.OnRestart(ev)
+		}
+
+		tm := time.NewTimer(ev.Backoff)
+		select {
+		case <-ctx.Done():
+			tm.Stop()
+			return err
+		case <-tm.C:
+		}
+	}
+}
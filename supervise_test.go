@@ -0,0 +1,70 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervise(t *testing.T) {
+	errRun := errors.New("run failed")
+	calls := 0
+	var events []pola.RestartEvent
+	r := pola.Supervise(pola.RunnerFunc(func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			panic("boom")
+		}
+		return errRun
+	}), pola.SupervisePolicy{
+		MaxRestarts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  4 * time.Millisecond,
+		Jitter:      0.5,
+		OnRestart: func(ev pola.RestartEvent) {
+			events = append(events, ev)
+		},
+	})
+
+	err := r.RunContext(context.Background())
+	assert.ErrorIs(t, err, pola.ErrTooManyRestarts)
+	assert.ErrorIs(t, err, errRun)
+	assert.Equal(t, 4, calls)
+	assert.Len(t, events, 3)
+
+	var pe *pola.PanicError
+	assert.ErrorAs(t, events[1].Err, &pe)
+	assert.Equal(t, "boom", pe.Value)
+	assert.NotEmpty(t, pe.Stack)
+	for _, ev := range events {
+		assert.LessOrEqual(t, ev.Backoff, 6*time.Millisecond)
+	}
+
+	// succeed after restart
+	calls = 0
+	r = pola.Supervise(pola.RunnerFunc(func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errRun
+		}
+		return nil
+	}), pola.SupervisePolicy{MinBackoff: time.Millisecond})
+	assert.NoError(t, r.RunContext(context.Background()))
+	assert.Equal(t, 3, calls)
+
+	// canceled while waiting for restart
+	ctx, cancel := context.WithCancel(context.Background())
+	r = pola.Supervise(pola.RunnerFunc(func(ctx context.Context) error {
+		return errRun
+	}), pola.SupervisePolicy{
+		MinBackoff: time.Hour,
+		OnRestart: func(pola.RestartEvent) {
+			cancel()
+		},
+	})
+	assert.ErrorIs(t, r.RunContext(ctx), errRun)
+}
@@ -0,0 +1,201 @@
+package pola
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// DrainPolicy determines what WorkerPool does with pending jobs
+// when its context is canceled.
+type DrainPolicy int
+
+const (
+	// DrainJobs finish in-flight jobs and jobs already taken from the source.
+	// Handlers receive context which is not canceled.
+	DrainJobs DrainPolicy = iota
+	// AbandonJobs cancel in-flight jobs. Jobs in the queue and job taken
+	// from the source but not queued are reported as *JobError[T]
+	// with the context error. RunContext does not wait for the source.
+	AbandonJobs
+)
+
+// JobError hold the job and the error returned by its handler.
+type JobError[T any] struct {
+	Job T
+	Err error
+}
+
+func (e *JobError[T]) Error() string {
+	return fmt.Sprintf("job %v: %v", e.Job, e.Err)
+}
+func (e *JobError[T]) Unwrap() error {
+	return e.Err
+}
+
+// WorkerPool is a Runner which process jobs with bounded number of workers.
+// Jobs are taken from the source (channel or iterator) into a bounded queue,
+// so a slow pool applies backpressure to the producer.
+type WorkerPool[T any] struct {
+	// Workers is number of concurrent workers (default 1).
+	Workers int
+	// QueueSize is the capacity of the jobs queue.
+	QueueSize int
+	// Policy applied when the context is canceled.
+	Policy DrainPolicy
+
+	handle func(ctx context.Context, job T) error
+	// feed pass jobs to emit until source is exhausted or emit returns false
+	feed func(ctx context.Context, emit func(T) bool)
+}
+
+// NewWorkerPool create pool which process jobs received from `ch`
+// until the channel is closed or the context is canceled.
+func NewWorkerPool[T any](workers int, ch <-chan T, handle func(ctx context.Context, job T) error) *WorkerPool[T] {
+	return &WorkerPool[T]{
+		Workers: workers,
+		handle:  handle,
+		feed: func(ctx context.Context, emit func(T) bool) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job, ok := <-ch:
+					if !ok || !emit(job) {
+						return
+					}
+				}
+			}
+		},
+	}
+}
+
+// NewSeqWorkerPool create pool which process jobs produced by `seq`
+// until the iterator is exhausted or the context is canceled.
+// The iterator is not aware of the context, so with DrainJobs, RunContext
+// wait for blocked iterator to yield or return. With AbandonJobs,
+// blocked iterator is left running, and its next job is dropped.
+func NewSeqWorkerPool[T any](workers int, seq iter.Seq[T], handle func(ctx context.Context, job T) error) *WorkerPool[T] {
+	return &WorkerPool[T]{
+		Workers: workers,
+		handle:  handle,
+		feed: func(ctx context.Context, emit func(T) bool) {
+			for job := range seq {
+				if !emit(job) {
+					return
+				}
+			}
+		},
+	}
+}
+
+// RunContext process jobs until the source is exhausted or ctx is canceled.
+// Errors returned by the handler are collected as *JobError[T] and joined.
+func (p *WorkerPool[T]) RunContext(ctx context.Context) error {
+	workers := max(p.Workers, 1)
+	queue := make(chan T, max(p.QueueSize, 0))
+
+	hctx := ctx
+	if p.Policy == DrainJobs {
+		hctx = context.WithoutCancel(ctx)
+	}
+
+	mu := sync.Mutex{}
+	var errs []error
+	addError := func(job T, err error) {
+		mu.Lock()
+		errs = append(errs, &JobError[T]{Job: job, Err: err})
+		mu.Unlock()
+	}
+	process := func(job T) {
+		if err := p.handle(hctx, job); err != nil {
+			addError(job, err)
+		}
+	}
+
+	// emit queue the job, which is already taken from the source.
+	// When canceled, the job is still processed (DrainJobs) or reported.
+	// Once abandoned, no more job is queued or reported.
+	feedMu := sync.Mutex{}
+	abandoned := false
+	emit := func(job T) bool {
+		feedMu.Lock()
+		defer feedMu.Unlock()
+		if abandoned {
+			return false
+		}
+		if ctx.Err() == nil {
+			select {
+			case queue <- job:
+				return true
+			case <-ctx.Done():
+			}
+		}
+		if p.Policy == DrainJobs {
+			// workers consume the queue until it is closed
+			queue <- job
+		} else {
+			addError(job, ctx.Err())
+		}
+		return false
+	}
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		defer close(queue)
+		p.feed(ctx, emit)
+	}()
+
+	// with DrainJobs, workers stop only when the feeder closes the queue
+	done := ctx.Done()
+	if p.Policy == DrainJobs {
+		done = nil
+	}
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job, ok := <-queue:
+					if !ok {
+						return
+					}
+					if done != nil && ctx.Err() != nil {
+						// abandoned, do not start new job
+						addError(job, ctx.Err())
+						return
+					}
+					process(job)
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p.Policy == DrainJobs {
+		<-fed
+		return errors.Join(errs...)
+	}
+
+	// report jobs left in the queue, without waiting for the source
+	feedMu.Lock()
+	abandoned = true
+	feedMu.Unlock()
+	for {
+		select {
+		case job, ok := <-queue:
+			if ok {
+				addError(job, ctx.Err())
+				continue
+			}
+		default:
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,177 @@
+package pola_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ipsusila/pola"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	errOdd := errors.New("odd")
+	var sum atomic.Int64
+	p := pola.NewSeqWorkerPool(4, slices.Values([]int{1, 2, 3, 4, 5, 6}), func(ctx context.Context, job int) error {
+		sum.Add(int64(job))
+		if job%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+	p.QueueSize = 2
+
+	err := p.RunContext(context.Background())
+	assert.Equal(t, int64(21), sum.Load())
+	assert.ErrorIs(t, err, errOdd)
+
+	var jerr *pola.JobError[int]
+	assert.ErrorAs(t, err, &jerr)
+	assert.Equal(t, 1, jerr.Job%2)
+
+	// channel source
+	ch := make(chan int)
+	go func() {
+		for i := range 100 {
+			ch <- i
+		}
+		close(ch)
+	}()
+	var count atomic.Int32
+	p = pola.NewWorkerPool(3, ch, func(ctx context.Context, job int) error {
+		count.Add(1)
+		return nil
+	})
+	assert.NoError(t, p.RunContext(context.Background()))
+	assert.Equal(t, int32(100), count.Load())
+}
+
+func TestWorkerPoolCancel(t *testing.T) {
+	for _, policy := range []pola.DrainPolicy{pola.DrainJobs, pola.AbandonJobs} {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int)
+		started := make(chan struct{})
+		var done atomic.Int32
+		p := pola.NewWorkerPool(1, ch, func(jctx context.Context, job int) error {
+			close(started)
+			<-ctx.Done()
+			if jctx.Err() != nil {
+				return jctx.Err()
+			}
+			done.Add(1)
+			return nil
+		})
+		p.Policy = policy
+
+		chErr := make(chan error, 1)
+		go func() {
+			chErr <- p.RunContext(ctx)
+		}()
+		ch <- 1
+		<-started
+		cancel()
+
+		err := <-chErr
+		if policy == pola.DrainJobs {
+			assert.NoError(t, err)
+			assert.Equal(t, int32(1), done.Load())
+		} else {
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, int32(0), done.Load())
+		}
+	}
+}
+
+func TestWorkerPoolCancelTakenJob(t *testing.T) {
+	for _, policy := range []pola.DrainPolicy{pola.DrainJobs, pola.AbandonJobs} {
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		second := make(chan struct{})
+		seq := func(yield func(int) bool) {
+			if !yield(1) {
+				return
+			}
+			close(second)
+			if !yield(2) {
+				return
+			}
+			yield(3)
+		}
+		var processed []int
+		p := pola.NewSeqWorkerPool(1, seq, func(jctx context.Context, job int) error {
+			if job == 1 {
+				close(started)
+				<-ctx.Done()
+			}
+			processed = append(processed, job)
+			return nil
+		})
+		p.Policy = policy
+
+		chErr := make(chan error, 1)
+		go func() {
+			chErr <- p.RunContext(ctx)
+		}()
+		<-started
+		<-second
+		cancel()
+
+		// job 2 is taken from the source while the worker is busy
+		err := <-chErr
+		if policy == pola.DrainJobs {
+			assert.NoError(t, err)
+			assert.Equal(t, []int{1, 2}, processed)
+		} else {
+			jerr := &pola.JobError[int]{}
+			if assert.ErrorAs(t, err, &jerr) {
+				assert.Equal(t, 2, jerr.Job)
+				assert.ErrorIs(t, jerr, context.Canceled)
+			}
+		}
+	}
+}
+
+func TestWorkerPoolAbandonQueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	queued := make(chan struct{})
+	block := make(chan struct{})
+	defer close(block)
+	seq := func(yield func(int) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+		// blocked source does not hang RunContext
+		close(queued)
+		<-block
+	}
+	p := pola.NewSeqWorkerPool(1, seq, func(jctx context.Context, job int) error {
+		close(started)
+		<-jctx.Done()
+		return nil
+	})
+	p.QueueSize = 2
+	p.Policy = pola.AbandonJobs
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- p.RunContext(ctx)
+	}()
+	<-started
+	<-queued
+	cancel()
+
+	var jobs []int
+	for _, err := range (<-chErr).(interface{ Unwrap() []error }).Unwrap() {
+		jerr := &pola.JobError[int]{}
+		if assert.ErrorAs(t, err, &jerr) {
+			assert.ErrorIs(t, jerr, context.Canceled)
+			jobs = append(jobs, jerr.Job)
+		}
+	}
+	assert.ElementsMatch(t, []int{2, 3}, jobs)
+}